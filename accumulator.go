@@ -0,0 +1,89 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import "time"
+
+// Accumulator accumulates amounts of data and calls Update
+// periodically with the amount added since the last update.
+//
+// In contrast to a ProgressReader, an Accumulator is not tied
+// to an io.Reader. Instead, amounts of data are added explicitly,
+// for example, whenever a network packet has been received.
+//
+// An Accumulator must not be used concurrently by multiple
+// goroutines.
+type Accumulator struct {
+	// Update, if non-nil, is called whenever Add is called
+	// and either the UpdateEvery period has ellapsed since
+	// the last update or UpdateAfter bytes have been added.
+	//
+	// As a special case, Update is called on every Add if
+	// UpdateEvery and UpdateAfter are both <= 0.
+	//
+	// Update receives the number of bytes added since the
+	// last update and the bandwidth at which these bytes
+	// have been added. The bandwidth is measured from the
+	// last update or, for the first update, from the first
+	// call of Add.
+	Update func(n Size, rate Bandwidth)
+
+	// UpdateEvery is the duration that has to ellapse
+	// between two Update calls.
+	//
+	// If UpdateEvery <= 0, Update may be called on every
+	// Add.
+	UpdateEvery time.Duration
+
+	// UpdateAfter is the number of bytes that have to
+	// be added before Update is called again.
+	//
+	// If UpdateAfter <= 0, Update may be called on every
+	// Add.
+	UpdateAfter Size
+
+	n, total   Size
+	lastUpdate time.Time
+}
+
+// Add adds n bytes to the accumulator and calls Update if
+// an update is due.
+func (a *Accumulator) Add(n Size) {
+	now := time.Now()
+	if a.lastUpdate.IsZero() {
+		a.lastUpdate = now
+	}
+	a.n += n
+	a.total += n
+
+	switch {
+	case a.UpdateEvery <= 0 && a.UpdateAfter <= 0:
+		a.update(now)
+	case a.UpdateAfter > 0 && a.n >= a.UpdateAfter:
+		a.update(now)
+	case a.UpdateEvery > 0 && now.Sub(a.lastUpdate) >= a.UpdateEvery:
+		a.update(now)
+	}
+}
+
+// Flush calls Update with the bytes added since the
+// last update, if any, regardless of UpdateEvery and
+// UpdateAfter.
+func (a *Accumulator) Flush() {
+	if a.n != 0 {
+		a.update(time.Now())
+	}
+}
+
+// Total returns the number of bytes added in total.
+func (a *Accumulator) Total() Size { return a.total }
+
+func (a *Accumulator) update(now time.Time) {
+	if a.Update != nil {
+		a.Update(a.n, Throughput(a.n, now.Sub(a.lastUpdate)))
+	}
+	a.n = 0
+	a.lastUpdate = now
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAccumulator(t *testing.T) {
+	for i, test := range accumulatorTests {
+		var updates []Size
+		a := Accumulator{
+			UpdateAfter: test.UpdateAfter,
+			Update: func(n Size, _ Bandwidth) {
+				updates = append(updates, n)
+			},
+		}
+		for _, n := range test.Add {
+			a.Add(n)
+		}
+		a.Flush()
+
+		if len(updates) != len(test.Updates) {
+			t.Fatalf("Test %d: got %d updates - want %d", i, len(updates), len(test.Updates))
+		}
+		for j := range updates {
+			if updates[j] != test.Updates[j] {
+				t.Fatalf("Test %d: update %d: got %v - want %v", i, j, updates[j], test.Updates[j])
+			}
+		}
+
+		var total Size
+		for _, n := range test.Add {
+			total += n
+		}
+		if a.Total() != total {
+			t.Fatalf("Test %d: got total %v - want %v", i, a.Total(), total)
+		}
+	}
+}
+
+var accumulatorTests = []struct {
+	UpdateAfter Size
+	Add         []Size
+	Updates     []Size
+}{
+	{UpdateAfter: 0, Add: nil, Updates: nil},                                               // 0
+	{UpdateAfter: 0, Add: []Size{KB, 2 * KB}, Updates: []Size{KB, 2 * KB}},                 // 1
+	{UpdateAfter: 2 * KB, Add: []Size{KB, KB, KB}, Updates: []Size{2 * KB, KB}},            // 2
+	{UpdateAfter: 2 * KB, Add: []Size{3 * KB, KB, KB, 0}, Updates: []Size{3 * KB, 2 * KB}}, // 3
+	{UpdateAfter: MB, Add: []Size{KB, KB}, Updates: []Size{2 * KB}},                        // 4
+}
+
+func TestAccumulator_UpdateEvery(t *testing.T) {
+	var updates int
+	var rate Bandwidth
+	a := Accumulator{
+		UpdateEvery: 10 * time.Millisecond,
+		Update: func(_ Size, r Bandwidth) {
+			updates++
+			rate = r
+		},
+	}
+
+	a.Add(MB)
+	a.Add(MB)
+	if updates != 0 {
+		t.Fatalf("got %d updates - want 0", updates)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	a.Add(MB)
+	if updates != 1 {
+		t.Fatalf("got %d updates - want 1", updates)
+	}
+	if rate <= 0 || rate > Throughput(3*MB, 20*time.Millisecond) {
+		t.Fatalf("invalid rate: %v", rate)
+	}
+}
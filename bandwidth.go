@@ -0,0 +1,415 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"sort"
+	"time"
+)
+
+// Common bandwidths for measuring data throughput.
+//
+// To count the number of units in a Bandwidth, divide:
+//
+//	bandwidth := mem.MBitPerSecond
+//	fmt.Print(int64(bandwidth / mem.KBitPerSecond)) // prints 1000
+//
+// To convert an integer of units to a Bandwidth, multiply:
+//
+//	megabytes := 10
+//	fmt.Print(mem.Bandwidth(megabytes)*mem.MBytePerSecond) // prints 10MB/s
+const (
+	BitPerSecond  Bandwidth = 1
+	KBitPerSecond           = 1000 * BitPerSecond
+	MBitPerSecond           = 1000 * KBitPerSecond
+	GBitPerSecond           = 1000 * MBitPerSecond
+	TBitPerSecond           = 1000 * GBitPerSecond
+	PBitPerSecond           = 1000 * TBitPerSecond
+	EBitPerSecond           = 1000 * PBitPerSecond
+
+	BytePerSecond  Bandwidth = 8 * BitPerSecond
+	KBytePerSecond           = 1000 * BytePerSecond
+	MBytePerSecond           = 1000 * KBytePerSecond
+	GBytePerSecond           = 1000 * MBytePerSecond
+	TBytePerSecond           = 1000 * GBytePerSecond
+	PBytePerSecond           = 1000 * TBytePerSecond
+	EBytePerSecond           = 1000 * PBytePerSecond
+
+	KiBytePerSecond Bandwidth = 1024 * BytePerSecond
+	MiBytePerSecond           = 1024 * KiBytePerSecond
+	GiBytePerSecond           = 1024 * MiBytePerSecond
+	TiBytePerSecond           = 1024 * GiBytePerSecond
+	PiBytePerSecond           = 1024 * TiBytePerSecond
+)
+
+// Bandwidth represents an amount of data transferred per second
+// as int64 number of bits per second. The largest representable
+// bandwidth is approximately 9223372 Tbit/s.
+type Bandwidth int64
+
+// Kilobits returns the bandwidth as floating point number of
+// kilobits per second (Kbit/s).
+func (b Bandwidth) Kilobits() float64 {
+	k := b / KBitPerSecond
+	r := b % KBitPerSecond
+	return float64(k) + float64(r)/1e3
+}
+
+// Megabits returns the bandwidth as floating point number of
+// megabits per second (Mbit/s).
+func (b Bandwidth) Megabits() float64 {
+	m := b / MBitPerSecond
+	r := b % MBitPerSecond
+	return float64(m) + float64(r)/1e6
+}
+
+// Gigabits returns the bandwidth as floating point number of
+// gigabits per second (Gbit/s).
+func (b Bandwidth) Gigabits() float64 {
+	g := b / GBitPerSecond
+	r := b % GBitPerSecond
+	return float64(g) + float64(r)/1e9
+}
+
+// Terabits returns the bandwidth as floating point number of
+// terabits per second (Tbit/s).
+func (b Bandwidth) Terabits() float64 {
+	t := b / TBitPerSecond
+	r := b % TBitPerSecond
+	return float64(t) + float64(r)/1e12
+}
+
+// Abs returns the absolute value of b. As a special case, math.MinInt64 is
+// converted to math.MaxInt64.
+func (b Bandwidth) Abs() Bandwidth {
+	return Bandwidth(abs(int64(b)))
+}
+
+// Truncate returns the result of rounding b towards zero to a multiple of m.
+// If m <= 0, Truncate returns b unchanged.
+func (b Bandwidth) Truncate(m Bandwidth) Bandwidth {
+	return Bandwidth(truncate(int64(b), int64(m)))
+}
+
+// Round returns the result of rounding b to the nearest multiple of m.
+// The rounding behavior for halfway values is to round away from zero.
+// If the result exceeds the maximum (or minimum) value that can be
+// stored in a Bandwidth, Round returns the maximum (or minimum) bandwidth.
+// If m <= 0, Round returns b unchanged.
+//
+// Round is the idiomatic way to round a bandwidth to a display step.
+// For example, to round b to whole megabits per second:
+//
+//	b.Round(mem.MBitPerSecond)
+func (b Bandwidth) Round(m Bandwidth) Bandwidth {
+	return Bandwidth(round(int64(b), int64(m)))
+}
+
+// Compare compares b to other and returns -1, 0 or +1 if b is
+// less than, equal to or greater than other, like Size.Compare.
+func (b Bandwidth) Compare(other Bandwidth) int {
+	switch {
+	case b < other:
+		return -1
+	case b > other:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsZero reports whether b is zero.
+func (b Bandwidth) IsZero() bool { return b == 0 }
+
+// IsNegative reports whether b is less than zero.
+func (b Bandwidth) IsNegative() bool { return b < 0 }
+
+// Sign returns -1 if b is negative, 0 if b is zero
+// and +1 if b is positive.
+func (b Bandwidth) Sign() int {
+	switch {
+	case b < 0:
+		return -1
+	case b > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Min returns the smaller of b and other.
+func (b Bandwidth) Min(other Bandwidth) Bandwidth {
+	if b < other {
+		return b
+	}
+	return other
+}
+
+// Max returns the larger of b and other.
+func (b Bandwidth) Max(other Bandwidth) Bandwidth {
+	if b > other {
+		return b
+	}
+	return other
+}
+
+// Clamp returns b limited to the range [min, max], like Size.Clamp.
+// If min is greater than max, Clamp returns b unchanged.
+func (b Bandwidth) Clamp(min, max Bandwidth) Bandwidth {
+	switch {
+	case min > max:
+		return b
+	case b < min:
+		return min
+	case b > max:
+		return max
+	default:
+		return b
+	}
+}
+
+// PerHour returns the amount of data transferred within one hour at
+// the bandwidth b. It saturates at the max. resp. min. representable
+// Size.
+func (b Bandwidth) PerHour() Size { return b.Transferred(time.Hour) }
+
+// PerDay returns the amount of data transferred within one day at
+// the bandwidth b. It saturates at the max. resp. min. representable
+// Size.
+func (b Bandwidth) PerDay() Size { return b.Transferred(24 * time.Hour) }
+
+// PerMonth returns the amount of data transferred within one month
+// of 30 days at the bandwidth b. It saturates at the max. resp. min.
+// representable Size.
+func (b Bandwidth) PerMonth() Size { return b.Transferred(30 * 24 * time.Hour) }
+
+// Transferred returns the amount of data transferred within the
+// duration d at the bandwidth b. The result is rounded towards zero
+// to whole bytes. Transferred returns 0 if d <= 0 and saturates at
+// the max. resp. min. representable Size.
+func (b Bandwidth) Transferred(d time.Duration) Size {
+	if d <= 0 {
+		return 0
+	}
+
+	neg := b < 0
+	u := uint64(b)
+	if neg {
+		u = -u // Two's complement - works for math.MinInt64, too
+	}
+
+	const bitsPerByteSecond = uint64(BytePerSecond) * uint64(time.Second)
+	hi, lo := bits.Mul64(u, uint64(d))
+	if hi >= bitsPerByteSecond { // Quotient does not fit into 64 bits
+		return Size(saturate(neg))
+	}
+	q, _ := bits.Div64(hi, lo, bitsPerByteSecond)
+	switch {
+	case neg && q > 1<<63:
+		return math.MinInt64
+	case neg:
+		return Size(-q)
+	case q > math.MaxInt64:
+		return math.MaxInt64
+	default:
+		return Size(q)
+	}
+}
+
+// Duration returns the time it takes to transfer n bytes at the
+// bandwidth b. The result is rounded up to the next nanosecond,
+// such that at least n bytes have been transferred after the
+// returned duration. Duration returns 0 if n <= 0.
+//
+// If b <= 0, no data is ever transferred and Duration returns the
+// max. representable time.Duration. The result saturates at the
+// max. representable time.Duration, too.
+func (b Bandwidth) Duration(n Size) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	if b <= 0 {
+		return math.MaxInt64
+	}
+
+	// Since n < 2^63 and 8 * time.Second < 2^33, the product
+	// of both fits into 128 bits even if n.Bits() would overflow.
+	hi, lo := bits.Mul64(uint64(n), 8*uint64(time.Second))
+	if hi >= uint64(b) { // Quotient does not fit into 64 bits
+		return math.MaxInt64
+	}
+	q, r := bits.Div64(hi, lo, uint64(b))
+	if r != 0 {
+		q++
+	}
+	if q > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(q)
+}
+
+// Display returns a string representing the bandwidth in bits per
+// second, like "1Gbit/s", for displaying approximate rates. If b is
+// within the relative tolerance of a whole number of units, Display
+// snaps b to it before formatting. Coarser units are preferred. For
+// example, with a tolerance of 0.01 (1%), 998 Mbit/s are displayed
+// as "1Gbit/s" and 99.92 Mbit/s as "100Mbit/s".
+//
+// If tolerance <= 0, or b is not within the tolerance of any whole
+// number of units, b is displayed exactly.
+func (b Bandwidth) Display(tolerance float64) string {
+	if tolerance > 0 && b != 0 {
+		var units = [...]Bandwidth{EBitPerSecond, PBitPerSecond, TBitPerSecond, GBitPerSecond, MBitPerSecond, KBitPerSecond}
+		for _, u := range units {
+			r := b.Round(u)
+			if r == 0 {
+				continue
+			}
+			if d := math.Abs(float64(r)-float64(b)) / math.Abs(float64(b)); d <= tolerance {
+				b = r
+				break
+			}
+		}
+	}
+
+	var buf [24]byte
+	return string(appendBandwidth(buf[:0], b, 'T', -1))
+}
+
+// String returns a string representing the bandwidth in the form "1.25MB/s".
+// The zero bandwidth formats as 0B/s.
+func (b Bandwidth) String() string { return FormatBandwidth(b, 'D', -1) }
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The bandwidth is encoded in the form "1.25MB/s", like String.
+func (b Bandwidth) MarshalText() ([]byte, error) { return []byte(b.String()), nil }
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The bandwidth is encoded as 8 byte big-endian two's complement
+// integer number of bits per second. This encoding is stable and
+// will not change.
+func (b Bandwidth) MarshalBinary() ([]byte, error) {
+	return binary.BigEndian.AppendUint64(make([]byte, 0, 8), uint64(b)), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// It decodes the 8 byte encoding produced by MarshalBinary.
+func (b *Bandwidth) UnmarshalBinary(data []byte) error {
+	v, err := unmarshalBinary(data, "bandwidth")
+	if err != nil {
+		return err
+	}
+	*b = Bandwidth(v)
+	return nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The text is parsed using ParseBandwidth. A single pair of
+// surrounding double quotes, like in "\"5MB/s\"", is removed
+// before parsing.
+func (b *Bandwidth) UnmarshalText(text []byte) error {
+	v, err := ParseBandwidth(string(unquote(text)))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// AverageBandwidth returns the arithmetic mean of the given samples,
+// rounded towards zero. It returns 0 if there are no samples.
+//
+// In contrast to summing up the samples first, AverageBandwidth
+// does not overflow for large bandwidths.
+func AverageBandwidth(samples ...Bandwidth) Bandwidth {
+	n := Bandwidth(len(samples))
+	if n == 0 {
+		return 0
+	}
+
+	// We compute the mean as q + r/n by summing up the quotients
+	// and remainders of all samples divided by n separately.
+	var q, r Bandwidth
+	for _, s := range samples {
+		q += s / n
+		r += s % n
+		switch {
+		case r >= n:
+			q, r = q+1, r-n
+		case r <= -n:
+			q, r = q-1, r+n
+		}
+	}
+
+	// Since |r| < n, q is the mean rounded towards zero
+	// unless q and r have different signs.
+	switch {
+	case q > 0 && r < 0:
+		q--
+	case q < 0 && r > 0:
+		q++
+	}
+	return q
+}
+
+// MedianBandwidth returns the median of the given samples. If the
+// number of samples is even, it returns the mean of the two middle
+// samples, rounded towards zero. It returns 0 if there are no
+// samples. In contrast to AverageBandwidth, the median is robust
+// against outliers, like short-lived spikes.
+func MedianBandwidth(samples ...Bandwidth) Bandwidth {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	sorted := make([]Bandwidth, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	m := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[m]
+	}
+	return AverageBandwidth(sorted[m-1], sorted[m])
+}
+
+// Throughput returns the bandwidth of transferring n bytes within
+// the duration d, i.e. n.Bits() per second. For example:
+//
+//	start := time.Now()
+//	n, err := io.Copy(dst, src)
+//	rate := mem.Throughput(mem.Size(n), time.Since(start))
+//
+// The result is rounded towards zero. Throughput returns 0 if d <= 0
+// and saturates at the max. resp. min. representable Bandwidth.
+func Throughput(n Size, d time.Duration) Bandwidth {
+	if d <= 0 {
+		return 0
+	}
+
+	neg := n < 0
+	u := uint64(n)
+	if neg {
+		u = -u // Two's complement - works for math.MinInt64, too
+	}
+	// Since u <= 2^63 and 8 * time.Second < 2^33, the product
+	// of both fits into 128 bits even if n.Bits() would overflow.
+	hi, lo := bits.Mul64(u, 8*uint64(time.Second))
+	if hi >= uint64(d) { // Quotient does not fit into 64 bits
+		return Bandwidth(saturate(neg))
+	}
+	q, _ := bits.Div64(hi, lo, uint64(d))
+	switch {
+	case neg && q <= 1<<63:
+		return Bandwidth(-q)
+	case !neg && q <= math.MaxInt64:
+		return Bandwidth(q)
+	default:
+		return Bandwidth(saturate(neg))
+	}
+}
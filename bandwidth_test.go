@@ -0,0 +1,391 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBandwidth_String(t *testing.T) {
+	for i, test := range bandwidthStringTests {
+		if s := test.Bandwidth.String(); s != test.String {
+			t.Fatalf("Test %d: got %s - want %s", i, s, test.String)
+		}
+	}
+}
+
+var bandwidthStringTests = []struct {
+	Bandwidth Bandwidth
+	String    string
+}{
+	{Bandwidth: 0, String: "0B/s"},                                         // 0
+	{Bandwidth: BitPerSecond, String: "0.125B/s"},                          // 1
+	{Bandwidth: BytePerSecond, String: "1B/s"},                             // 2
+	{Bandwidth: MBytePerSecond, String: "1MB/s"},                           // 3
+	{Bandwidth: -MBytePerSecond, String: "-1MB/s"},                         // 4
+	{Bandwidth: MBitPerSecond, String: "125KB/s"},                          // 5
+	{Bandwidth: 1*GBytePerSecond + 250*MBytePerSecond, String: "1.25GB/s"}, // 6
+}
+
+func TestBandwidth_MarshalBinary(t *testing.T) {
+	for i, test := range bandwidthMarshalTextTests {
+		data, err := test.MarshalBinary()
+		if err != nil {
+			t.Fatalf("Test %d: failed to marshal bandwidth: %v", i, err)
+		}
+		var b Bandwidth
+		if err = b.UnmarshalBinary(data); err != nil {
+			t.Fatalf("Test %d: failed to unmarshal bandwidth: %v", i, err)
+		}
+		if b != test {
+			t.Fatalf("Test %d: got %d - want %d", i, b, test)
+		}
+	}
+	var b Bandwidth
+	if err := b.UnmarshalBinary(make([]byte, 4)); err == nil {
+		t.Fatal("Unmarshaling a truncated bandwidth should have failed")
+	}
+}
+
+func TestBandwidth_MarshalText(t *testing.T) {
+	for i, test := range bandwidthMarshalTextTests {
+		text, err := test.MarshalText()
+		if err != nil {
+			t.Fatalf("Test %d: failed to marshal bandwidth: %v", i, err)
+		}
+		var b Bandwidth
+		if err = b.UnmarshalText(text); err != nil {
+			t.Fatalf("Test %d: failed to unmarshal bandwidth: %v", i, err)
+		}
+		if b != test {
+			t.Fatalf("Test %d: got %d - want %d", i, b, test)
+		}
+	}
+}
+
+var bandwidthMarshalTextTests = []Bandwidth{
+	0,                                     // 0
+	BitPerSecond,                          // 1
+	-BitPerSecond,                         // 2
+	BytePerSecond,                         // 3
+	MBitPerSecond,                         // 4
+	-MBytePerSecond,                       // 5
+	1*GBytePerSecond + 250*MBytePerSecond, // 6
+	-(3*TBitPerSecond + 7*BitPerSecond),   // 7
+}
+
+func TestBandwidth_UnmarshalText(t *testing.T) {
+	for i, test := range bandwidthUnmarshalTextTests {
+		var b Bandwidth
+		err := b.UnmarshalText([]byte(test.Text))
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to unmarshal bandwidth: %v", i, err)
+		}
+		if b != test.Bandwidth {
+			t.Fatalf("Test %d: got %d - want %d", i, b, test.Bandwidth)
+		}
+	}
+}
+
+var bandwidthUnmarshalTextTests = []struct {
+	Text       string
+	Bandwidth  Bandwidth
+	ShouldFail bool
+}{
+	{Text: "100Mbit/s", Bandwidth: 100 * MBitPerSecond},   // 0
+	{Text: `"100Mbit/s"`, Bandwidth: 100 * MBitPerSecond}, // 1
+	{Text: `"-1.5MB/s"`, Bandwidth: -12 * MBitPerSecond},  // 2
+	{Text: `""`, ShouldFail: true},                        // 3
+	{Text: `""1MB/s""`, ShouldFail: true},                 // 4
+	{Text: `1MB/s"`, ShouldFail: true},                    // 5
+	{Text: `'1MB/s'`, ShouldFail: true},                   // 6
+}
+
+func TestThroughput(t *testing.T) {
+	for i, test := range throughputTests {
+		if b := Throughput(test.Size, test.Duration); b != test.Bandwidth {
+			t.Fatalf("Test %d: got %d - want %d", i, b, test.Bandwidth)
+		}
+	}
+}
+
+var throughputTests = []struct {
+	Size      Size
+	Duration  time.Duration
+	Bandwidth Bandwidth
+}{
+	{Size: MB, Duration: 0, Bandwidth: 0},                                        // 0
+	{Size: MB, Duration: -time.Second, Bandwidth: 0},                             // 1
+	{Size: MB, Duration: time.Second, Bandwidth: MBytePerSecond},                 // 2
+	{Size: 500 * MB, Duration: 4 * time.Second, Bandwidth: 125 * MBytePerSecond}, // 3
+	{Size: -MB, Duration: time.Second, Bandwidth: -MBytePerSecond},               // 4
+	{Size: MB, Duration: time.Millisecond, Bandwidth: GBytePerSecond},            // 5
+	{Size: math.MaxInt64, Duration: time.Nanosecond, Bandwidth: math.MaxInt64},   // 6
+	{Size: math.MinInt64, Duration: time.Nanosecond, Bandwidth: math.MinInt64},   // 7
+	{Size: math.MaxInt64, Duration: time.Second, Bandwidth: math.MaxInt64},       // 8
+	{Size: math.MaxInt64, Duration: 4 * time.Second, Bandwidth: math.MaxInt64},   // 9
+	{Size: math.MinInt64, Duration: 16 * time.Second, Bandwidth: -1 << 62},       // 10
+	{Size: 3, Duration: 2 * time.Second, Bandwidth: 12 * BitPerSecond},           // 11
+	{Size: 1, Duration: 3 * time.Second, Bandwidth: 2 * BitPerSecond},            // 12
+}
+
+func TestBandwidth_Round(t *testing.T) {
+	for i, test := range bandwidthRoundTests {
+		if r := test.Bandwidth.Round(test.M); r != test.Round {
+			t.Fatalf("Test %d: got %d - want %d", i, r, test.Round)
+		}
+	}
+}
+
+var bandwidthRoundTests = []struct {
+	Bandwidth Bandwidth
+	M         Bandwidth
+	Round     Bandwidth
+}{
+	{Bandwidth: 0, M: MBitPerSecond, Round: 0},                                                     // 0
+	{Bandwidth: 94*MBitPerSecond + 499*KBitPerSecond, M: MBitPerSecond, Round: 94 * MBitPerSecond}, // 1
+	{Bandwidth: 94*MBitPerSecond + 500*KBitPerSecond, M: MBitPerSecond, Round: 95 * MBitPerSecond}, // 2
+	{Bandwidth: -1500 * KBitPerSecond, M: MBitPerSecond, Round: -2 * MBitPerSecond},                // 3
+	{Bandwidth: -1499 * KBitPerSecond, M: MBitPerSecond, Round: -MBitPerSecond},                    // 4
+	{Bandwidth: 1337 * MBitPerSecond, M: GBitPerSecond, Round: GBitPerSecond},                      // 5
+	{Bandwidth: 1337 * MBitPerSecond, M: 0, Round: 1337 * MBitPerSecond},                           // 6
+	{Bandwidth: math.MaxInt64, M: MBitPerSecond, Round: math.MaxInt64},                             // 7
+	{Bandwidth: math.MinInt64, M: MBitPerSecond, Round: math.MinInt64},                             // 8
+	{Bandwidth: math.MaxInt64, M: GBytePerSecond, Round: math.MaxInt64},                            // 9
+	{Bandwidth: math.MinInt64, M: GBytePerSecond, Round: math.MinInt64},                            // 10
+	{Bandwidth: math.MaxInt64 - MBitPerSecond, M: MBitPerSecond, Round: math.MaxInt64 - 775807},    // 11
+	{Bandwidth: -1001 * KBitPerSecond, M: MBitPerSecond, Round: -MBitPerSecond},                    // 12
+}
+
+func TestBandwidth_Truncate(t *testing.T) {
+	for i, test := range bandwidthTruncateTests {
+		if r := test.Bandwidth.Truncate(test.M); r != test.Truncate {
+			t.Fatalf("Test %d: got %d - want %d", i, r, test.Truncate)
+		}
+	}
+}
+
+var bandwidthTruncateTests = []struct {
+	Bandwidth Bandwidth
+	M         Bandwidth
+	Truncate  Bandwidth
+}{
+	{Bandwidth: 0, M: MBitPerSecond, Truncate: 0},                                                     // 0
+	{Bandwidth: 94*MBitPerSecond + 999*KBitPerSecond, M: MBitPerSecond, Truncate: 94 * MBitPerSecond}, // 1
+	{Bandwidth: -1999 * KBitPerSecond, M: MBitPerSecond, Truncate: -MBitPerSecond},                    // 2
+	{Bandwidth: 1337 * MBitPerSecond, M: -1, Truncate: 1337 * MBitPerSecond},                          // 3
+	{Bandwidth: math.MaxInt64, M: MBitPerSecond, Truncate: math.MaxInt64 - 775807},                    // 4
+	{Bandwidth: math.MinInt64, M: MBitPerSecond, Truncate: math.MinInt64 + 775808},                    // 5
+}
+
+func TestAverageBandwidth(t *testing.T) {
+	for i, test := range averageBandwidthTests {
+		if avg := AverageBandwidth(test.Samples...); avg != test.Average {
+			t.Fatalf("Test %d: got average %d - want %d", i, avg, test.Average)
+		}
+		if median := MedianBandwidth(test.Samples...); median != test.Median {
+			t.Fatalf("Test %d: got median %d - want %d", i, median, test.Median)
+		}
+	}
+}
+
+var averageBandwidthTests = []struct {
+	Samples []Bandwidth
+	Average Bandwidth
+	Median  Bandwidth
+}{
+	{Samples: nil, Average: 0, Median: 0},                                                                              // 0
+	{Samples: []Bandwidth{MBitPerSecond}, Average: MBitPerSecond, Median: MBitPerSecond},                               // 1
+	{Samples: []Bandwidth{1, 2}, Average: 1, Median: 1},                                                                // 2
+	{Samples: []Bandwidth{3, 1, 2}, Average: 2, Median: 2},                                                             // 3
+	{Samples: []Bandwidth{10, 10, 10, 1000}, Average: 257, Median: 10},                                                 // 4
+	{Samples: []Bandwidth{-1, -2}, Average: -1, Median: -1},                                                            // 5
+	{Samples: []Bandwidth{2, 2, -3}, Average: 0, Median: 2},                                                            // 6
+	{Samples: []Bandwidth{-2, -2, 3}, Average: 0, Median: -2},                                                          // 7
+	{Samples: []Bandwidth{5, -1, -1}, Average: 1, Median: -1},                                                          // 8
+	{Samples: []Bandwidth{math.MaxInt64, math.MaxInt64}, Average: math.MaxInt64, Median: math.MaxInt64},                // 9
+	{Samples: []Bandwidth{math.MinInt64, math.MinInt64}, Average: math.MinInt64, Median: math.MinInt64},                // 10
+	{Samples: []Bandwidth{math.MaxInt64, math.MaxInt64 - 1}, Average: math.MaxInt64 - 1, Median: math.MaxInt64 - 1},    // 11
+	{Samples: []Bandwidth{math.MaxInt64, math.MinInt64}, Average: 0, Median: 0},                                        // 12
+	{Samples: []Bandwidth{math.MaxInt64, math.MaxInt64, math.MaxInt64}, Average: math.MaxInt64, Median: math.MaxInt64}, // 13
+}
+
+func TestBandwidth_Display(t *testing.T) {
+	for i, test := range bandwidthDisplayTests {
+		if s := test.Bandwidth.Display(test.Tolerance); s != test.String {
+			t.Fatalf("Test %d: got %s - want %s", i, s, test.String)
+		}
+	}
+}
+
+var bandwidthDisplayTests = []struct {
+	Bandwidth Bandwidth
+	Tolerance float64
+	String    string
+}{
+	{Bandwidth: 0, Tolerance: 0.01, String: "0bit/s"},                             // 0
+	{Bandwidth: 998 * MBitPerSecond, Tolerance: 0.01, String: "1Gbit/s"},          // 1
+	{Bandwidth: 998 * MBitPerSecond, Tolerance: 0.001, String: "998Mbit/s"},       // 2
+	{Bandwidth: 998 * MBitPerSecond, Tolerance: 0, String: "998Mbit/s"},           // 3
+	{Bandwidth: 99920 * KBitPerSecond, Tolerance: 0.01, String: "100Mbit/s"},      // 4
+	{Bandwidth: 999200 * KBitPerSecond, Tolerance: 0.0001, String: "999.2Mbit/s"}, // 5
+	{Bandwidth: 999200 * KBitPerSecond, Tolerance: 0.0005, String: "999Mbit/s"},   // 6
+	{Bandwidth: -1005 * MBitPerSecond, Tolerance: 0.01, String: "-1Gbit/s"},       // 7
+	{Bandwidth: 1500 * MBitPerSecond, Tolerance: 0.01, String: "1.5Gbit/s"},       // 8
+	{Bandwidth: 1234567 * BitPerSecond, Tolerance: -1, String: "1.234567Mbit/s"},  // 9
+	{Bandwidth: 3 * BitPerSecond, Tolerance: 0.5, String: "3bit/s"},               // 10
+	{Bandwidth: 995 * BitPerSecond, Tolerance: 0.01, String: "1Kbit/s"},           // 11
+	{Bandwidth: math.MaxInt64, Tolerance: 0.01, String: "9.223Ebit/s"},            // 12
+	{Bandwidth: 1998 * TBitPerSecond, Tolerance: 0.01, String: "2Pbit/s"},         // 13
+}
+
+func TestBandwidth_PerHour(t *testing.T) {
+	for i, test := range bandwidthPerPeriodTests {
+		if s := test.Bandwidth.PerHour(); s != test.Hour {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test.Hour)
+		}
+		if s := test.Bandwidth.PerDay(); s != test.Day {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test.Day)
+		}
+		if s := test.Bandwidth.PerMonth(); s != test.Month {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test.Month)
+		}
+	}
+}
+
+var bandwidthPerPeriodTests = []struct {
+	Bandwidth        Bandwidth
+	Hour, Day, Month Size
+}{
+	{Bandwidth: 0, Hour: 0, Day: 0, Month: 0},                                                 // 0
+	{Bandwidth: BytePerSecond, Hour: 3600, Day: 86400, Month: 2592000},                        // 1
+	{Bandwidth: BitPerSecond, Hour: 450, Day: 10800, Month: 324000},                           // 2
+	{Bandwidth: 3 * BitPerSecond, Hour: 1350, Day: 32400, Month: 972000},                      // 3
+	{Bandwidth: MBitPerSecond, Hour: 450 * MB, Day: 10800 * MB, Month: 324 * GB},              // 4
+	{Bandwidth: -MBytePerSecond, Hour: -3600 * MB, Day: -86400 * MB, Month: -2592 * GB},       // 5
+	{Bandwidth: TBytePerSecond, Hour: 3600 * TB, Day: 86400 * TB, Month: 2592 * PB},           // 6
+	{Bandwidth: 10 * TBytePerSecond, Hour: 36 * PB, Day: 864 * PB, Month: math.MaxInt64},      // 7
+	{Bandwidth: -10 * TBytePerSecond, Hour: -36 * PB, Day: -864 * PB, Month: math.MinInt64},   // 8
+	{Bandwidth: math.MaxInt64, Hour: math.MaxInt64, Day: math.MaxInt64, Month: math.MaxInt64}, // 9
+	{Bandwidth: math.MinInt64, Hour: math.MinInt64, Day: math.MinInt64, Month: math.MinInt64}, // 10
+}
+
+func TestBandwidth_Compare(t *testing.T) {
+	for i, test := range bandwidthCompareTests {
+		if c := test.A.Compare(test.B); c != test.Cmp {
+			t.Fatalf("Test %d: got %d - want %d", i, c, test.Cmp)
+		}
+		if c := test.B.Compare(test.A); c != -test.Cmp {
+			t.Fatalf("Test %d: got %d - want %d", i, c, -test.Cmp)
+		}
+		min, max := test.A, test.B
+		if test.Cmp > 0 {
+			min, max = max, min
+		}
+		if m := test.A.Min(test.B); m != min {
+			t.Fatalf("Test %d: got min %d - want %d", i, m, min)
+		}
+		if m := test.A.Max(test.B); m != max {
+			t.Fatalf("Test %d: got max %d - want %d", i, m, max)
+		}
+	}
+}
+
+var bandwidthCompareTests = []struct {
+	A, B Bandwidth
+	Cmp  int
+}{
+	{A: 0, B: 0, Cmp: 0},                                // 0
+	{A: MBitPerSecond, B: MBytePerSecond, Cmp: -1},      // 1
+	{A: KBytePerSecond, B: -KBytePerSecond, Cmp: 1},     // 2
+	{A: math.MinInt64, B: math.MaxInt64, Cmp: -1},       // 3
+	{A: GBitPerSecond, B: 125 * MBytePerSecond, Cmp: 0}, // 4
+}
+
+func TestBandwidth_Clamp(t *testing.T) {
+	for i, test := range bandwidthClampTests {
+		if c := test.Bandwidth.Clamp(test.Min, test.Max); c != test.Clamp {
+			t.Fatalf("Test %d: got %d - want %d", i, c, test.Clamp)
+		}
+	}
+}
+
+var bandwidthClampTests = []struct {
+	Bandwidth, Min, Max Bandwidth
+	Clamp               Bandwidth
+}{
+	{Bandwidth: KBitPerSecond, Min: MBitPerSecond, Max: GBitPerSecond, Clamp: MBitPerSecond},         // 0
+	{Bandwidth: TBitPerSecond, Min: MBitPerSecond, Max: GBitPerSecond, Clamp: GBitPerSecond},         // 1
+	{Bandwidth: 5 * MBitPerSecond, Min: MBitPerSecond, Max: GBitPerSecond, Clamp: 5 * MBitPerSecond}, // 2
+	{Bandwidth: TBitPerSecond, Min: GBitPerSecond, Max: MBitPerSecond, Clamp: TBitPerSecond},         // 3
+}
+
+func TestBandwidth_Duration(t *testing.T) {
+	for i, test := range bandwidthDurationTests {
+		if d := test.Bandwidth.Duration(test.Size); d != test.Duration {
+			t.Fatalf("Test %d: got %v - want %v", i, d, test.Duration)
+		}
+	}
+}
+
+var bandwidthDurationTests = []struct {
+	Bandwidth Bandwidth
+	Size      Size
+	Duration  time.Duration
+}{
+	{Bandwidth: MBytePerSecond, Size: MB, Duration: time.Second},                   // 0
+	{Bandwidth: 100 * MBitPerSecond, Size: GB, Duration: 80 * time.Second},         // 1
+	{Bandwidth: 3 * BitPerSecond, Size: 1, Duration: 2666666667 * time.Nanosecond}, // 2
+	{Bandwidth: math.MaxInt64, Size: 1, Duration: time.Nanosecond},                 // 3
+	{Bandwidth: MBytePerSecond, Size: 0, Duration: 0},                              // 4
+	{Bandwidth: MBytePerSecond, Size: -MB, Duration: 0},                            // 5
+	{Bandwidth: 0, Size: MB, Duration: math.MaxInt64},                              // 6
+	{Bandwidth: -MBytePerSecond, Size: MB, Duration: math.MaxInt64},                // 7
+	{Bandwidth: BitPerSecond, Size: math.MaxInt64, Duration: math.MaxInt64},        // 8
+}
+
+func TestBandwidth_Transferred(t *testing.T) {
+	for i, test := range bandwidthTransferredTests {
+		if s := test.Bandwidth.Transferred(test.Duration); s != test.Size {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test.Size)
+		}
+	}
+}
+
+var bandwidthTransferredTests = []struct {
+	Bandwidth Bandwidth
+	Duration  time.Duration
+	Size      Size
+}{
+	{Bandwidth: MBytePerSecond, Duration: time.Second, Size: MB},             // 0
+	{Bandwidth: 100 * MBitPerSecond, Duration: 80 * time.Second, Size: GB},   // 1
+	{Bandwidth: MBytePerSecond, Duration: time.Millisecond, Size: KB},        // 2
+	{Bandwidth: 15 * BitPerSecond, Duration: time.Second, Size: 1},           // 3
+	{Bandwidth: -15 * BitPerSecond, Duration: time.Second, Size: -1},         // 4
+	{Bandwidth: MBytePerSecond, Duration: 0, Size: 0},                        // 5
+	{Bandwidth: MBytePerSecond, Duration: -time.Second, Size: 0},             // 6
+	{Bandwidth: math.MaxInt64, Duration: math.MaxInt64, Size: math.MaxInt64}, // 7
+	{Bandwidth: math.MinInt64, Duration: math.MaxInt64, Size: math.MinInt64}, // 8
+}
+
+func TestBandwidth_Sign(t *testing.T) {
+	for i, test := range signTests {
+		b := Bandwidth(test.Value)
+		if sign := b.Sign(); sign != test.Sign {
+			t.Fatalf("Test %d: got sign %d - want %d", i, sign, test.Sign)
+		}
+		if z := b.IsZero(); z != (test.Sign == 0) {
+			t.Fatalf("Test %d: got IsZero %v - want %v", i, z, test.Sign == 0)
+		}
+		if n := b.IsNegative(); n != (test.Sign < 0) {
+			t.Fatalf("Test %d: got IsNegative %v - want %v", i, n, test.Sign < 0)
+		}
+	}
+}
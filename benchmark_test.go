@@ -24,6 +24,51 @@ func BenchmarkFormatSize(b *testing.B) {
 	b.Run("-1mb-d-∞", func(b *testing.B) { formatSize(MB, 'd', -1, b) })
 	b.Run("1mb-b-∞", func(b *testing.B) { formatSize(MB, 'b', -1, b) })
 	b.Run("1mb-b-4", func(b *testing.B) { formatSize(MB, 'd', 4, b) })
+	b.Run("5gb-d-2", func(b *testing.B) { formatSize(5*GB, 'd', 2, b) })
+	b.Run("5gib-b-2", func(b *testing.B) { formatSize(5*GiB, 'b', 2, b) })
+	b.Run("1.111mb-d-∞", func(b *testing.B) { formatSize(MB+111*KB, 'd', -1, b) })
+	b.Run("1.111mb-d-4", func(b *testing.B) { formatSize(MB+111*KB, 'd', 4, b) })
+}
+
+func BenchmarkSize_AppendString(b *testing.B) {
+	appendString := func(s Size, b *testing.B) {
+		buf := make([]byte, 0, 64)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf = s.AppendString(buf[:0])
+		}
+	}
+	b.Run("0b", func(b *testing.B) { appendString(0, b) })
+	b.Run("1mb", func(b *testing.B) { appendString(MB, b) })
+	b.Run("1.048576mb", func(b *testing.B) { appendString(MiB, b) })
+}
+
+func BenchmarkAppendSize(b *testing.B) {
+	appendSize := func(s Size, fmt byte, prec int, b *testing.B) {
+		buf := make([]byte, 0, 64)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf = AppendSize(buf[:0], s, fmt, prec)
+		}
+	}
+	b.Run("0b-d-∞", func(b *testing.B) { appendSize(0, 'd', -1, b) })
+	b.Run("1mb-d-∞", func(b *testing.B) { appendSize(MB, 'd', -1, b) })
+	b.Run("1mb-b-∞", func(b *testing.B) { appendSize(MB, 'b', -1, b) })
+	b.Run("5gib-b-2", func(b *testing.B) { appendSize(5*GiB, 'b', 2, b) })
+	b.Run("1.111mb-d-4", func(b *testing.B) { appendSize(MB+111*KB, 'd', 4, b) })
+}
+
+func BenchmarkAppendBitSize(b *testing.B) {
+	appendSize := func(s BitSize, fmt byte, prec int, b *testing.B) {
+		buf := make([]byte, 0, 64)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf = AppendBitSize(buf[:0], s, fmt, prec)
+		}
+	}
+	b.Run("0bit-d-∞", func(b *testing.B) { appendSize(0, 'd', -1, b) })
+	b.Run("1mbit-d-∞", func(b *testing.B) { appendSize(MBit, 'd', -1, b) })
+	b.Run("1.5mbit-d-2", func(b *testing.B) { appendSize(1500*KBit, 'd', 2, b) })
 }
 
 func BenchmarkFormatBitSize(b *testing.B) {
@@ -50,6 +95,21 @@ func BenchmarkParseSize(b *testing.B) {
 	}
 	b.Run("0b", func(b *testing.B) { parseSize("0b", b) })
 	b.Run("1mb", func(b *testing.B) { parseSize("1mb", b) })
+	b.Run("1.5MiB", func(b *testing.B) { parseSize("1.5MiB", b) })
+}
+
+func BenchmarkParseSizeBytes(b *testing.B) {
+	parseSize := func(s []byte, b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := ParseSizeBytes(s); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("0b", func(b *testing.B) { parseSize([]byte("0b"), b) })
+	b.Run("1mb", func(b *testing.B) { parseSize([]byte("1mb"), b) })
+	b.Run("1.5MiB", func(b *testing.B) { parseSize([]byte("1.5MiB"), b) })
 }
 
 func BenchmarkParseBitSize(b *testing.B) {
@@ -85,6 +145,6 @@ func BenchmarkProgressReader(b *testing.B) {
 			b.Fatal(err)
 		}
 		r.Reset(data)
-		p.n, p.total, p.err = 0, 0, nil
+		p.ResetAll(r)
 	}
 }
@@ -4,6 +4,11 @@
 
 package mem
 
+import (
+	"encoding/binary"
+	"fmt"
+)
+
 // Common sizes when measuring amounts of data in bits.
 //
 // To count the number of units in a BitSize, divide:
@@ -21,10 +26,16 @@ const (
 	MBit         = 1000 * KBit
 	GBit         = 1000 * MBit
 	TBit         = 1000 * GBit
+	PBit         = 1000 * TBit
+	EBit         = 1000 * PBit
 )
 
+// Nibble is a half-byte (4 bits). It is commonly used by low-level
+// formats, like packed BCD, that encode one decimal digit per nibble.
+const Nibble BitSize = 4 * Bit
+
 // BitSize represents an amount of data as int64 number of bits.
-// The largest representable size is approximately 9223372 Tbit.
+// The largest representable size is approximately 9.2 Ebit.
 type BitSize int64
 
 // Bytes returns b as number of bytes and any remaining bits,
@@ -37,6 +48,76 @@ func (b BitSize) Bytes() (Size, BitSize) {
 	return Size(b / 8), b % 8
 }
 
+// BytesFloor returns b as number of bytes rounded down to the
+// next whole byte. For example, 12 bits are 1 byte. For negative
+// sizes, BytesFloor rounds towards negative infinity.
+func (b BitSize) BytesFloor() Size {
+	bytes, bits := b.Bytes()
+	if bits < 0 {
+		bytes--
+	}
+	return bytes
+}
+
+// BytesCeil returns b as number of bytes rounded up to the next
+// whole byte, such that the bytes can hold all bits of b. For
+// example, 12 bits are 2 bytes. For negative sizes, BytesCeil
+// rounds towards positive infinity.
+func (b BitSize) BytesCeil() Size {
+	bytes, bits := b.Bytes()
+	if bits > 0 {
+		bytes++
+	}
+	return bytes
+}
+
+// Compare compares b to other and returns -1, 0 or +1 if b is
+// less than, equal to or greater than other, like Size.Compare.
+func (b BitSize) Compare(other BitSize) int {
+	switch {
+	case b < other:
+		return -1
+	case b > other:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsZero reports whether b is zero.
+func (b BitSize) IsZero() bool { return b == 0 }
+
+// IsNegative reports whether b is less than zero.
+func (b BitSize) IsNegative() bool { return b < 0 }
+
+// Sign returns -1 if b is negative, 0 if b is zero
+// and +1 if b is positive.
+func (b BitSize) Sign() int {
+	switch {
+	case b < 0:
+		return -1
+	case b > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// RoundToBytes returns b as number of bytes rounded to the nearest
+// whole byte. The rounding behavior for halfway values, like 12 bits,
+// is to round away from zero. For example, 11 bits are 1 byte while
+// 12 bits are 2 bytes.
+func (b BitSize) RoundToBytes() Size {
+	bytes, bits := b.Bytes()
+	switch {
+	case bits >= 4:
+		bytes++
+	case bits <= -4:
+		bytes--
+	}
+	return bytes
+}
+
 // Kilobits returns the size as floating point number of kilobits (Kbit).
 func (b BitSize) Kilobits() float64 {
 	m := b / KBit
@@ -65,6 +146,20 @@ func (b BitSize) Terabits() float64 {
 	return float64(m) + float64(r)/1e12
 }
 
+// Petabits returns the size as floating point number of petabits (Pbit).
+func (b BitSize) Petabits() float64 {
+	m := b / PBit
+	r := b % PBit
+	return float64(m) + float64(r)/1e15
+}
+
+// Exabits returns the size as floating point number of exabits (Ebit).
+func (b BitSize) Exabits() float64 {
+	m := b / EBit
+	r := b % EBit
+	return float64(m) + float64(r)/1e18
+}
+
 // Abs returns the absolute value of b. As a special case, math.MinInt64 is
 // converted to math.MaxInt64.
 func (b BitSize) Abs() BitSize {
@@ -80,12 +175,102 @@ func (b BitSize) Truncate(m BitSize) BitSize {
 // Round returns the result of rounding b to the nearest multiple of m.
 // The rounding behavior for halfway values is to round away from zero.
 // If the result exceeds the maximum (or minimum) value that can be
-// stored in a Size, Round returns the maximum (or minimum) size.
+// stored in a BitSize, Round returns the maximum (or minimum) bit size.
 // If m <= 0, Round returns b unchanged.
+//
+// To round b to a whole number of units, pass the unit as m. For
+// example, to round b to whole megabits:
+//
+//	b.Round(mem.MBit)
 func (b BitSize) Round(m BitSize) BitSize {
 	return BitSize(round(int64(b), int64(m)))
 }
 
+// Min returns the smaller of b and other.
+func (b BitSize) Min(other BitSize) BitSize {
+	if b < other {
+		return b
+	}
+	return other
+}
+
+// Max returns the larger of b and other.
+func (b BitSize) Max(other BitSize) BitSize {
+	if b > other {
+		return b
+	}
+	return other
+}
+
+// Clamp returns b limited to the range [min, max], like Size.Clamp.
+// If min is greater than max, Clamp returns b unchanged.
+func (b BitSize) Clamp(min, max BitSize) BitSize {
+	switch {
+	case min > max:
+		return b
+	case b < min:
+		return min
+	case b > max:
+		return max
+	default:
+		return b
+	}
+}
+
 // String returns a string representing the bit size in the form "1.25Mbit".
 // The zero size formats as 0Bit.
 func (b BitSize) String() string { return FormatBitSize(b, 'D', -1) }
+
+// Format implements the fmt.Formatter interface. The verbs %v, %s
+// and %d format b using decimal units, like "1.25Mbit". The precision,
+// width and flags are handled like by Size.Format. Any other verb,
+// like %q or %x, formats the String representation of b.
+func (b BitSize) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's', 'd':
+	default:
+		fmt.Fprintf(f, formatString(f, verb), b.String())
+		return
+	}
+	prec, ok := f.Precision()
+	if !ok {
+		prec = -1
+	}
+
+	var buf [32]byte
+	writeFormatted(f, AppendBitSize(buf[:0], b, 'D', prec), b >= 0)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The bit size is encoded in the form "1.25Mbit", like String.
+func (b BitSize) MarshalText() ([]byte, error) { return []byte(b.String()), nil }
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The bit size is encoded as 8 byte big-endian two's complement integer
+// number of bits. This encoding is stable and will not change.
+func (b BitSize) MarshalBinary() ([]byte, error) {
+	return binary.BigEndian.AppendUint64(make([]byte, 0, 8), uint64(b)), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// It decodes the 8 byte encoding produced by MarshalBinary.
+func (b *BitSize) UnmarshalBinary(data []byte) error {
+	v, err := unmarshalBinary(data, "bit size")
+	if err != nil {
+		return err
+	}
+	*b = BitSize(v)
+	return nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The text is parsed using ParseBitSize. A single pair of surrounding
+// double quotes, like in "\"5Mbit\"", is removed before parsing.
+func (b *BitSize) UnmarshalText(text []byte) error {
+	v, err := ParseBitSize(string(unquote(text)))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
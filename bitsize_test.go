@@ -5,6 +5,7 @@
 package mem
 
 import (
+	"fmt"
 	"math"
 	"testing"
 )
@@ -26,7 +27,115 @@ var bitsizeStringTests = []struct {
 	{Size: 8*KBit + 172*Bit, String: "8.172Kbit"},             // 2
 	{Size: MBit, String: "1Mbit"},                             // 3
 	{Size: -MBit, String: "-1Mbit"},                           // 4
-	{Size: math.MaxInt64, String: "9223372.036854775807Tbit"}, // 5
+	{Size: math.MaxInt64, String: "9.223372036854775807Ebit"}, // 5
+	{Size: 2500 * TBit, String: "2.5Pbit"},                    // 6
+	{Size: 999 * TBit, String: "999Tbit"},                     // 7
+	{Size: -EBit, String: "-1Ebit"},                           // 8
+}
+
+func TestBitSize_Format(t *testing.T) {
+	for i, test := range bitsizeFormatTests {
+		if s := fmt.Sprintf(test.Format, test.Size); s != test.String {
+			t.Fatalf("Test %d: got '%s' - want '%s'", i, s, test.String)
+		}
+	}
+}
+
+var bitsizeFormatTests = []struct {
+	Format string
+	Size   BitSize
+	String string
+}{
+	{Format: "%v", Size: 0, String: "0Bit"},                 // 0
+	{Format: "%s", Size: 1250 * KBit, String: "1.25Mbit"},   // 1
+	{Format: "%.1d", Size: 1250 * KBit, String: "1.2Mbit"},  // 2
+	{Format: "%10d|", Size: MBit, String: "     1Mbit|"},    // 3
+	{Format: "%-+8d|", Size: MBit, String: "+1Mbit  |"},     // 4
+	{Format: "% d", Size: -MBit, String: "-1Mbit"},          // 5
+	{Format: "%b", Size: KBit, String: "%!b(string=1Kbit)"}, // 6
+	{Format: "%q", Size: KBit, String: `"1Kbit"`},           // 7
+	{Format: "%x", Size: KBit, String: "314b626974"},        // 8
+	{Format: "%8q|", Size: KBit, String: ` "1Kbit"|`},       // 9
+}
+
+func TestBitSize_MarshalBinary(t *testing.T) {
+	for i, test := range bitsizeMarshalTextTests {
+		data, err := test.MarshalBinary()
+		if err != nil {
+			t.Fatalf("Test %d: failed to marshal bit size: %v", i, err)
+		}
+		var b BitSize
+		if err = b.UnmarshalBinary(data); err != nil {
+			t.Fatalf("Test %d: failed to unmarshal bit size: %v", i, err)
+		}
+		if b != test {
+			t.Fatalf("Test %d: got %d - want %d", i, b, test)
+		}
+	}
+	var b BitSize
+	if err := b.UnmarshalBinary(nil); err == nil {
+		t.Fatal("Unmarshaling an empty bit size should have failed")
+	}
+}
+
+func TestBitSize_MarshalText(t *testing.T) {
+	for i, test := range bitsizeMarshalTextTests {
+		text, err := test.MarshalText()
+		if err != nil {
+			t.Fatalf("Test %d: failed to marshal bit size: %v", i, err)
+		}
+		var b BitSize
+		if err = b.UnmarshalText(text); err != nil {
+			t.Fatalf("Test %d: failed to unmarshal bit size: %v", i, err)
+		}
+		if b != test {
+			t.Fatalf("Test %d: got %d - want %d", i, b, test)
+		}
+	}
+}
+
+var bitsizeMarshalTextTests = []BitSize{
+	0,                   // 0
+	Bit,                 // 1
+	-Bit,                // 2
+	8*KBit + 172*Bit,    // 3
+	-(8*KBit + 172*Bit), // 4
+	Nibble,              // 5
+	10 * TBit,           // 6
+	-MBit,               // 7
+	1200 * PBit,         // 8
+	math.MaxInt64,       // 9
+	math.MinInt64,       // 10
+}
+
+func TestBitSize_UnmarshalText(t *testing.T) {
+	for i, test := range bitsizeUnmarshalTextTests {
+		var b BitSize
+		err := b.UnmarshalText([]byte(test.Text))
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to unmarshal bit size: %v", i, err)
+		}
+		if b != test.Size {
+			t.Fatalf("Test %d: got %d - want %d", i, b, test.Size)
+		}
+	}
+}
+
+var bitsizeUnmarshalTextTests = []struct {
+	Text       string
+	Size       BitSize
+	ShouldFail bool
+}{
+	{Text: "5Mbit", Size: 5 * MBit},       // 0
+	{Text: `"5Mbit"`, Size: 5 * MBit},     // 1
+	{Text: `"-1.5Kbit"`, Size: -1500},     // 2
+	{Text: `""`, ShouldFail: true},        // 3
+	{Text: `""5Mbit""`, ShouldFail: true}, // 4
+	{Text: `"5Mbit`, ShouldFail: true},    // 5
+	{Text: `'5Mbit'`, ShouldFail: true},   // 6
 }
 
 func TestBitSize_Bytes(t *testing.T) {
@@ -57,6 +166,61 @@ var bitsizeBytesTests = []struct {
 	{Size: math.MinInt64, Bytes: math.MinInt64 / 8, Bits: 0}, // 8
 }
 
+func TestBitSize_BytesFloorCeil(t *testing.T) {
+	for i, test := range bitsizeBytesFloorCeilTests {
+		if floor := test.Size.BytesFloor(); floor != test.Floor {
+			t.Fatalf("Test %d: got floor %d - want %d", i, floor, test.Floor)
+		}
+		if ceil := test.Size.BytesCeil(); ceil != test.Ceil {
+			t.Fatalf("Test %d: got ceil %d - want %d", i, ceil, test.Ceil)
+		}
+	}
+}
+
+var bitsizeBytesFloorCeilTests = []struct {
+	Size        BitSize
+	Floor, Ceil Size
+}{
+	{Size: 0, Floor: 0, Ceil: 0},                                                   // 0
+	{Size: 1, Floor: 0, Ceil: 1},                                                   // 1
+	{Size: 8, Floor: 1, Ceil: 1},                                                   // 2
+	{Size: 12, Floor: 1, Ceil: 2},                                                  // 3
+	{Size: -1, Floor: -1, Ceil: 0},                                                 // 4
+	{Size: -12, Floor: -2, Ceil: -1},                                               // 5
+	{Size: -16, Floor: -2, Ceil: -2},                                               // 6
+	{Size: KBit + 1, Floor: 125, Ceil: 126},                                        // 7
+	{Size: math.MaxInt64, Floor: math.MaxInt64 / 8, Ceil: math.MaxInt64/8 + 1},     // 8
+	{Size: math.MinInt64, Floor: math.MinInt64 / 8, Ceil: math.MinInt64 / 8},       // 9
+	{Size: math.MinInt64 + 1, Floor: math.MinInt64 / 8, Ceil: math.MinInt64/8 + 1}, // 10
+}
+
+func TestBitSize_RoundToBytes(t *testing.T) {
+	for i, test := range bitsizeRoundToBytesTests {
+		if r := test.Size.RoundToBytes(); r != test.Bytes {
+			t.Fatalf("Test %d: got %d - want %d", i, r, test.Bytes)
+		}
+	}
+}
+
+var bitsizeRoundToBytesTests = []struct {
+	Size  BitSize
+	Bytes Size
+}{
+	{Size: 0, Bytes: 0},                                   // 0
+	{Size: 3, Bytes: 0},                                   // 1
+	{Size: 4, Bytes: 1},                                   // 2
+	{Size: 11, Bytes: 1},                                  // 3
+	{Size: 12, Bytes: 2},                                  // 4
+	{Size: 65, Bytes: 8},                                  // 5
+	{Size: -3, Bytes: 0},                                  // 6
+	{Size: -4, Bytes: -1},                                 // 7
+	{Size: -12, Bytes: -2},                                // 8
+	{Size: math.MaxInt64, Bytes: math.MaxInt64/8 + 1},     // 9
+	{Size: math.MinInt64, Bytes: math.MinInt64 / 8},       // 10
+	{Size: math.MinInt64 + 4, Bytes: math.MinInt64 / 8},   // 11
+	{Size: math.MinInt64 + 5, Bytes: math.MinInt64/8 + 1}, // 12
+}
+
 func TestBitSize_Kilobits(t *testing.T) {
 	for i, test := range bitsizeConvertTests {
 		if bits := test.Size.Kilobits(); bits != test.KBit {
@@ -81,6 +245,29 @@ func TestBitSize_Gigabits(t *testing.T) {
 	}
 }
 
+func TestBitSize_PetabitsExabits(t *testing.T) {
+	for i, test := range bitsizePetabitsExabitsTests {
+		if bits := test.Size.Petabits(); bits != test.PBit {
+			t.Fatalf("Test %d: got %f - want %f", i, bits, test.PBit)
+		}
+		if bits := test.Size.Exabits(); bits != test.EBit {
+			t.Fatalf("Test %d: got %f - want %f", i, bits, test.EBit)
+		}
+	}
+}
+
+var bitsizePetabitsExabitsTests = []struct {
+	Size BitSize
+	PBit float64
+	EBit float64
+}{
+	{Size: 0, PBit: 0, EBit: 0},                      // 0
+	{Size: PBit, PBit: 1, EBit: 0.001},               // 1
+	{Size: 2500 * TBit, PBit: 2.5, EBit: 0.0025},     // 2
+	{Size: -3 * EBit, PBit: -3000, EBit: -3},         // 3
+	{Size: 1*EBit + 500*PBit, PBit: 1500, EBit: 1.5}, // 4
+}
+
 func TestBitSize_Terabits(t *testing.T) {
 	for i, test := range bitsizeConvertTests {
 		if bits := test.Size.Terabits(); bits != test.TBit {
@@ -132,3 +319,134 @@ var bitsizeConvertTests = []struct {
 		TBit: 117.000000004,
 	},
 }
+
+func TestBitSize_MinMax(t *testing.T) {
+	for i, test := range bitsizeMinMaxTests {
+		if min := test.A.Min(test.B); min != test.Min {
+			t.Fatalf("Test %d: got min %d - want %d", i, min, test.Min)
+		}
+		if min := test.B.Min(test.A); min != test.Min {
+			t.Fatalf("Test %d: got min %d - want %d", i, min, test.Min)
+		}
+		if max := test.A.Max(test.B); max != test.Max {
+			t.Fatalf("Test %d: got max %d - want %d", i, max, test.Max)
+		}
+		if max := test.B.Max(test.A); max != test.Max {
+			t.Fatalf("Test %d: got max %d - want %d", i, max, test.Max)
+		}
+	}
+}
+
+var bitsizeMinMaxTests = []struct {
+	A, B     BitSize
+	Min, Max BitSize
+}{
+	{A: 0, B: 0, Min: 0, Max: 0},                                                 // 0
+	{A: KBit, B: MBit, Min: KBit, Max: MBit},                                     // 1
+	{A: -Bit, B: Nibble, Min: -Bit, Max: Nibble},                                 // 2
+	{A: math.MinInt64, B: math.MaxInt64, Min: math.MinInt64, Max: math.MaxInt64}, // 3
+}
+
+func TestBitSize_Compare(t *testing.T) {
+	for i, test := range bitsizeCompareTests {
+		if c := test.A.Compare(test.B); c != test.Cmp {
+			t.Fatalf("Test %d: got %d - want %d", i, c, test.Cmp)
+		}
+		if c := test.B.Compare(test.A); c != -test.Cmp {
+			t.Fatalf("Test %d: got %d - want %d", i, c, -test.Cmp)
+		}
+	}
+}
+
+var bitsizeCompareTests = []struct {
+	A, B BitSize
+	Cmp  int
+}{
+	{A: 0, B: 0, Cmp: 0},                          // 0
+	{A: Bit, B: Nibble, Cmp: -1},                  // 1
+	{A: MBit, B: -KBit, Cmp: 1},                   // 2
+	{A: math.MinInt64, B: math.MaxInt64, Cmp: -1}, // 3
+}
+
+func TestBitSize_Sign(t *testing.T) {
+	for i, test := range signTests {
+		b := BitSize(test.Value)
+		if sign := b.Sign(); sign != test.Sign {
+			t.Fatalf("Test %d: got sign %d - want %d", i, sign, test.Sign)
+		}
+		if z := b.IsZero(); z != (test.Sign == 0) {
+			t.Fatalf("Test %d: got IsZero %v - want %v", i, z, test.Sign == 0)
+		}
+		if n := b.IsNegative(); n != (test.Sign < 0) {
+			t.Fatalf("Test %d: got IsNegative %v - want %v", i, n, test.Sign < 0)
+		}
+	}
+}
+
+func TestBitSize_Clamp(t *testing.T) {
+	for i, test := range bitsizeClampTests {
+		if c := test.Size.Clamp(test.Min, test.Max); c != test.Clamp {
+			t.Fatalf("Test %d: got %d - want %d", i, c, test.Clamp)
+		}
+	}
+}
+
+var bitsizeClampTests = []struct {
+	Size, Min, Max BitSize
+	Clamp          BitSize
+}{
+	{Size: Bit, Min: Nibble, Max: KBit, Clamp: Nibble}, // 0
+	{Size: MBit, Min: Nibble, Max: KBit, Clamp: KBit},  // 1
+	{Size: 100, Min: Nibble, Max: KBit, Clamp: 100},    // 2
+	{Size: MBit, Min: KBit, Max: Nibble, Clamp: MBit},  // 3
+}
+
+func TestBitSize_Round(t *testing.T) {
+	for i, test := range bitsizeRoundTests {
+		if r := test.Size.Round(test.M); r != test.Round {
+			t.Fatalf("Test %d: got %d - want %d", i, r, test.Round)
+		}
+	}
+}
+
+var bitsizeRoundTests = []struct {
+	Size  BitSize
+	M     BitSize
+	Round BitSize
+}{
+	{Size: 0, M: MBit, Round: 0},                            // 0
+	{Size: 12*MBit + 499*KBit, M: MBit, Round: 12 * MBit},   // 1
+	{Size: 12*MBit + 500*KBit, M: MBit, Round: 13 * MBit},   // 2
+	{Size: -12*MBit - 500*KBit, M: MBit, Round: -13 * MBit}, // 3
+	{Size: 1499 * MBit, M: GBit, Round: GBit},               // 4
+	{Size: 1500 * MBit, M: GBit, Round: 2 * GBit},           // 5
+	{Size: 1500 * MBit, M: 0, Round: 1500 * MBit},           // 6
+	{Size: math.MaxInt64, M: MBit, Round: math.MaxInt64},    // 7
+	{Size: math.MinInt64, M: MBit, Round: math.MinInt64},    // 8
+	{Size: math.MaxInt64, M: GBit, Round: math.MaxInt64},    // 9
+	{Size: math.MinInt64, M: GBit, Round: math.MinInt64},    // 10
+	{Size: math.MaxInt64, M: TBit, Round: 9223372 * TBit},   // 11
+	{Size: -12*MBit - KBit, M: MBit, Round: -12 * MBit},     // 12
+	{Size: -12*MBit - 499*KBit, M: MBit, Round: -12 * MBit}, // 13
+}
+
+func TestBitSize_Truncate(t *testing.T) {
+	for i, test := range bitsizeTruncateTests {
+		if r := test.Size.Truncate(test.M); r != test.Truncate {
+			t.Fatalf("Test %d: got %d - want %d", i, r, test.Truncate)
+		}
+	}
+}
+
+var bitsizeTruncateTests = []struct {
+	Size     BitSize
+	M        BitSize
+	Truncate BitSize
+}{
+	{Size: 0, M: MBit, Truncate: 0},                            // 0
+	{Size: 12*MBit + 999*KBit, M: MBit, Truncate: 12 * MBit},   // 1
+	{Size: -12*MBit - 999*KBit, M: MBit, Truncate: -12 * MBit}, // 2
+	{Size: 1500 * MBit, M: -1, Truncate: 1500 * MBit},          // 3
+	{Size: math.MaxInt64, M: TBit, Truncate: 9223372 * TBit},   // 4
+	{Size: math.MinInt64, M: TBit, Truncate: -9223372 * TBit},  // 5
+}
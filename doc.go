@@ -30,9 +30,18 @@
 //	│ Mbit │ 1000 Kbit │  │ MB   │ 1000 KB   │  │ MiB  │ 1024 KiB  │
 //	│ Gbit │ 1000 Mbit │  │ GB   │ 1000 MB   │  │ GiB  │ 1024 MiB  │
 //	│ Tbit │ 1000 Gbit │  │ TB   │ 1000 GB   │  │ TiB  │ 1024 GiB  │
-//	│      │           │  │ PB   │ 1000 TB   │  │ PiB  │ 1024 TiB  │
+//	│ Pbit │ 1000 Tbit │  │ PB   │ 1000 TB   │  │ PiB  │ 1024 TiB  │
+//	│ Ebit │ 1000 Pbit │  │ EB   │ 1000 PB   │  │ EiB  │ 1024 PiB  │
 //	└──────┴───────────┘  └──────┴───────────┘  └──────┴───────────┘
 //
+// The rate at which data is transferred is represented by the
+// Bandwidth type as number of bits per second. For example, the
+// bandwidth of transferring 1 MB within one second is 1 MB/s or
+// equivalently 8 Mbit/s. Measured bandwidths can be rounded to common
+// display steps using Round. For example:
+//
+//	rate := bandwidth.Round(mem.MBitPerSecond) // Round to whole Mbit/s
+//
 // # Formatting
 //
 // Sizes can be formatted and displayed in various units and with
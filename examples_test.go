@@ -39,6 +39,14 @@ func ExampleFormatSize() {
 	// 2.0005TiB
 }
 
+func ExampleFormatRate() {
+	fmt.Println(mem.FormatRate(500*mem.MB, 4*time.Second, 'D', -1))
+	fmt.Println(mem.FormatRate(1*mem.GiB, 3*time.Second, 'D', 2))
+	// Output:
+	// 125MB/s
+	// 357.91MB/s
+}
+
 func ExampleSize_String() {
 	fmt.Println(1 * mem.MB)
 	fmt.Println(1*mem.GB + 500*mem.MB)
@@ -113,6 +121,33 @@ func ExampleProgressReader() {
 	// Done
 }
 
+func ExampleFormatProgress() {
+	r := bytes.NewReader(make([]byte, 1*mem.MB))
+	p := mem.NewProgressReader(r, 500*time.Millisecond, func(p mem.Progress) {
+		fmt.Println(mem.FormatProgress(p.Total, mem.Size(r.Size())))
+	})
+	if _, err := io.Copy(io.Discard, p); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// 8.192KB / 1MB (0.8%)
+	// 1MB / 1MB (100.0%)
+}
+
+func ExampleProgress_Percent() {
+	r := bytes.NewReader(make([]byte, 1*mem.MB))
+	p := mem.NewProgressReader(r, 500*time.Millisecond, func(p mem.Progress) {
+		fmt.Printf("Copied %s (%.1f%%)\n", p.Total, p.Percent())
+	})
+	p.Expected = mem.Size(r.Size())
+	if _, err := io.Copy(io.Discard, p); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// Copied 8.192KB (0.8%)
+	// Copied 1MB (100.0%)
+}
+
 func ExampleProgressReader_UpdateAfter() {
 	r := bytes.NewReader(make([]byte, 1*mem.MB))
 	p := mem.NewProgressReader(r, 500*time.Millisecond, func(p mem.Progress) {
@@ -0,0 +1,19 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import "flag"
+
+// SizeFlag defines a Size flag with the specified name, default
+// value and usage string on flag.CommandLine. The returned value
+// is the address of a Size variable that stores the value of the
+// flag. The flag accepts any size string that ParseSize accepts,
+// like "-max-upload 20MiB".
+func SizeFlag(name string, value Size, usage string) *Size {
+	s := new(Size)
+	*s = value
+	flag.CommandLine.Var(s, name, usage)
+	return s
+}
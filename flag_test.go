@@ -0,0 +1,63 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+var _ flag.Value = (*Size)(nil) // compiler check
+
+func TestSize_Set(t *testing.T) {
+	for i, test := range sizeFlagSetTests {
+		f := flag.NewFlagSet("test", flag.ContinueOnError)
+		f.SetOutput(io.Discard)
+
+		size := test.Default
+		f.Var(&size, "size", "")
+		err := f.Parse(test.Args)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to parse flags: %v", i, err)
+		}
+		if size != test.Size {
+			t.Fatalf("Test %d: got %d - want %d", i, size, test.Size)
+		}
+	}
+}
+
+var sizeFlagSetTests = []struct {
+	Args       []string
+	Default    Size
+	Size       Size
+	ShouldFail bool
+}{
+	{Args: nil, Default: KB, Size: KB},                                       // 0
+	{Args: []string{"-size", "20MiB"}, Default: KB, Size: 20 * MiB},          // 1
+	{Args: []string{"-size=1.5GB"}, Size: 1500 * MB},                         // 2
+	{Args: []string{"-size", (8*KiB + 1).String()}, Size: 8*KiB + 1},         // 3
+	{Args: []string{"-size", "20"}, Default: KB, Size: KB, ShouldFail: true}, // 4
+	{Args: []string{"-size", "20  MiB"}, Size: 0, ShouldFail: true},          // 5
+}
+
+func TestSizeFlag(t *testing.T) {
+	defer func(cmd *flag.FlagSet) { flag.CommandLine = cmd }(flag.CommandLine)
+	flag.CommandLine = flag.NewFlagSet("test", flag.ContinueOnError)
+
+	size := SizeFlag("max-upload", 5*MiB, "maximum upload size")
+	if *size != 5*MiB {
+		t.Fatalf("got %d - want %d", *size, 5*MiB)
+	}
+	if err := flag.CommandLine.Parse([]string{"-max-upload", "20MiB"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+	if *size != 20*MiB {
+		t.Fatalf("got %d - want %d", *size, 20*MiB)
+	}
+}
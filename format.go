@@ -6,22 +6,98 @@ package mem
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"math"
+	"math/bits"
+	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
+var (
+	// ErrSyntax indicates that a value does not have the
+	// right syntax for the target type.
+	ErrSyntax = errors.New("invalid syntax")
+
+	// ErrRange indicates that a value is out of range
+	// for the target type.
+	ErrRange = errors.New("value out of range")
+)
+
+// A ParseError records a failed attempt to parse a string,
+// for example a size string parsed by ParseSize.
+//
+// Err is either ErrSyntax or ErrRange such that callers can
+// distinguish malformed values from values that are too large
+// using errors.Is.
+type ParseError struct {
+	Type  string // The type of the value, like "size" or "bit size"
+	Input string // The string that failed to parse
+	Part  string // The offending part of Input, like an unknown unit
+	Err   error  // The reason the parsing failed, ErrSyntax or ErrRange
+}
+
+func (e *ParseError) Error() string {
+	return "mem: invalid " + e.Type + " '" + e.Input + "': " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error, ErrSyntax or ErrRange.
+func (e *ParseError) Unwrap() error { return e.Err }
+
 // ParseSize parses a size string. A size string is a
 // possibly signed decimal number with an optional
 // fraction and a unit suffix, such as "64KB" or "1MiB".
 //
 // A string may be a decimal or binary size representation.
 // Valid units are:
-//   - decimal: "b", "kb", "mb", "gb", "tb", "pb"
-//   - binary:  "b", "kib", "mib", "gib", "tib", "pib"
-func ParseSize(s string) (Size, error) {
+//   - decimal: "b", "kb", "mb", "gb", "tb", "pb", "eb"
+//   - binary:  "b", "kib", "mib", "gib", "tib", "pib", "eib"
+//
+// In addition, the single-letter units "k", "m", "g", "t" and "p",
+// that are commonly used by command line tools, are accepted as
+// decimal units. For example, "512K" is equal to "512KB". Likewise,
+// "ki", "mi", "gi", "ti" and "pi" are accepted as binary units,
+// such that "512Ki" is equal to "512KiB".
+//
+// The integer part of the number may contain commas as thousands
+// separators, such as "1,073,741,824B". Each comma must be followed
+// by exactly three digits and preceded by one to three digits.
+//
+// Fractions of a byte, like in "1.0005KB", are rounded to the nearest
+// byte. Halfway values are rounded away from zero.
+//
+// The number may have an exponent, such as "1.5e3MB" or "2E-3GB",
+// that consists of an 'e' or 'E' followed by a possibly signed
+// decimal integer. Numbers with an exponent must not contain commas.
+//
+// The number and the unit may be separated by a single space or
+// tab, such as in "5 MB" or "512\tKiB".
+//
+// ParseSize is strict and rejects strings with surrounding or
+// other whitespace (" 5MB", "5  MB"), with a unit in front of the
+// number ("MB5"), without a unit ("5"), with more than one sign
+// ("--5MB") or with misplaced commas ("1,00MB", "1,,000MB").
+// ParseSizeFlexible accepts some of these forms.
+//
+// If s is not a valid size string, ParseSize returns a *ParseError
+// that wraps ErrSyntax, or ErrRange if the size does not fit into
+// a Size.
+func ParseSize(s string) (Size, error) { return parseSize(s, sizeUnit[string]) }
+
+// ParseSizeBytes parses a size string like ParseSize but operates
+// directly on the byte slice b. In contrast to ParseSize(string(b)),
+// it does not allocate unless b is not a valid size string.
+func ParseSizeBytes(b []byte) (Size, error) { return parseSize(b, sizeUnit[[]byte]) }
+
+// parseSize parses the size string s. It uses unitOf to look up
+// the Size unit of the unit suffix. Units must be greater than 0.
+func parseSize[T string | []byte](s T, unitOf func(T) (Size, bool)) (Size, error) {
 	orig := s
-	if s == "" {
-		return 0, errors.New("mem: invalid size '" + orig + "'")
+	if len(s) == 0 {
+		return 0, &ParseError{Type: "size", Err: ErrSyntax}
 	}
 
 	var neg bool
@@ -29,147 +105,600 @@ func ParseSize(s string) (Size, error) {
 		neg = c == '-'
 		s = s[1:]
 	}
+	// An expanded exponent, like "1500" for "1.5e3", is written to
+	// buf on the stack, such that parsing it does not allocate.
+	var buf [64]byte
+	expanded, rest, part, err := expandExponent(buf[:0], s)
+	if err != nil {
+		return 0, &ParseError{Type: "size", Input: string(orig), Part: string(part), Err: err}
+	}
 
-	var dot bool
-	var m, r uint64
-	var l uint64 = 1
-	for i, c := range s {
+	var m, r, l uint64
+	var n int
+	if expanded != nil {
+		m, r, l, n, err = parseNumber(expanded)
+	} else {
+		m, r, l, n, err = parseNumber(s)
+		rest = s[n:]
+	}
+	switch {
+	case err == ErrRange && expanded != nil:
+		return 0, &ParseError{Type: "size", Input: string(orig), Part: string(expanded[:n]), Err: err}
+	case err == ErrRange:
+		return 0, &ParseError{Type: "size", Input: string(orig), Part: string(s[:n]), Err: err}
+	case err != nil:
+		return 0, &ParseError{Type: "size", Input: string(orig), Part: string(s[n:]), Err: err}
+	case len(rest) == 0:
+		return 0, &ParseError{Type: "size", Input: string(orig), Err: ErrSyntax} // Missing unit
+	}
+
+	unit, ok := unitOf(unitSuffix(rest))
+	if !ok {
+		return 0, &ParseError{Type: "size", Input: string(orig), Part: string(rest), Err: ErrSyntax}
+	}
+	if m <= 1<<63/uint64(unit) {
+		// Since m * unit <= 2^63 and the fraction is at most
+		// one unit, v cannot overflow.
+		v := m*uint64(unit) + fraction(r, l, uint64(unit))
+		switch {
+		case neg && v <= 1<<63:
+			return Size(-v), nil
+		case !neg && v == 1<<63 && r > 0:
+			return math.MaxInt64, nil // Fraction has been rounded up to 2^63
+		case !neg && v <= math.MaxInt64:
+			return Size(v), nil
+		}
+	}
+	if expanded != nil {
+		return 0, &ParseError{Type: "size", Input: string(orig), Part: string(expanded), Err: ErrRange}
+	}
+	return 0, &ParseError{Type: "size", Input: string(orig), Part: string(s[:n]), Err: ErrRange}
+}
+
+// parseNumber parses the leading decimal number of s, with optional
+// thousands separators and fraction, as m + r/l and returns the
+// number of bytes n it consists of. If the number is invalid, it
+// returns ErrSyntax and the start n of the invalid part, or ErrRange
+// and the length n of the number that does not fit into an int64.
+func parseNumber[T string | []byte](s T) (m, r, l uint64, n int, err error) {
+	var dot, grouped bool
+	var group int // Number of digits since the start or the last ','
+	l = 1
+	for ; n < len(s); n++ {
+		c := s[n]
 		if dot {
-			switch {
-			case c >= '0' && c <= '9':
+			if c < '0' || c > '9' {
+				return m, r, l, n, nil
+			}
+			// Ignore fraction digits beyond the precision of
+			// l since they cannot affect the result anyway.
+			if l <= math.MaxUint64/10 {
 				r = r*10 + uint64(c-'0')
 				l *= 10
-			default:
-				unit, ok := sizeUnits[s[i:]]
-				if !ok {
-					return 0, errors.New("mem: invalid size '" + orig + "'")
-				}
-				R := uint64(float64(r) / float64(l) * float64(unit))
+			}
+			continue
+		}
 
-				if neg {
-					if m > 1<<63/uint64(unit) {
-						return 0, errors.New("mem: invalid size '" + orig + "'")
-					}
-					return -1 * (Size(m)*unit + Size(R)), nil
-				}
-				if m > math.MaxInt64/uint64(unit) {
-					return 0, errors.New("mem: invalid size '" + orig + "'")
-				}
+		switch {
+		case c >= '0' && c <= '9':
+			if m > math.MaxInt64/10 {
+				return 0, 0, 0, n + 1, ErrRange
+			}
+			m = m*10 + uint64(c-'0')
+			group++
+		case c == ',':
+			// Thousands separators must separate groups of
+			// exactly three digits, like in "1,073,741,824B".
+			// The first group may contain one to three digits.
+			if group == 0 || group > 3 || (grouped && group != 3) {
+				return 0, 0, 0, n, ErrSyntax
+			}
+			grouped, group = true, 0
+		case c == '.':
+			if grouped && group != 3 {
+				return 0, 0, 0, n, ErrSyntax
+			}
+			dot = true
+		default:
+			if n == 0 || (grouped && group != 3) {
+				return 0, 0, 0, n, ErrSyntax
+			}
+			return m, r, l, n, nil
+		}
+	}
+	if !dot && grouped && group != 3 {
+		return 0, 0, 0, n, ErrSyntax
+	}
+	return m, r, l, n, nil
+}
+
+// expandExponent applies the exponent of the leading number of s,
+// if any, to the number. It appends the expanded number to dst and
+// returns it together with the rest of s. For example, "1.5e3MB"
+// expands to "1500" and "MB", and "25E-3KB" to "0.025" and "KB".
+// For strings without an exponent, like "1.5eb", it returns nil and
+// s unchanged.
+//
+// The expanded number is at most 61 bytes long. Hence, appending to
+// a dst with a capacity of 64 bytes does not allocate.
+//
+// If the number cannot be expanded, expandExponent returns the number,
+// including its exponent, and either ErrSyntax or ErrRange.
+func expandExponent[T string | []byte](dst []byte, s T) (expanded []byte, rest, part T, err error) {
+	n := 0
+	for n < len(s) && ((s[n] >= '0' && s[n] <= '9') || s[n] == '.' || s[n] == ',') {
+		n++
+	}
+	if n+1 >= len(s) || (s[n] != 'e' && s[n] != 'E') {
+		return nil, s, s[:0], nil
+	}
 
-				s := Size(m)*unit + Size(R)
-				if s == math.MinInt64 {
-					return math.MaxInt64, nil
+	end := n + 1
+	var negExp bool
+	if c := s[end]; c == '+' || c == '-' {
+		negExp = c == '-'
+		end++
+	}
+	if end >= len(s) || s[end] < '0' || s[end] > '9' {
+		return nil, s, s[:0], nil // Not an exponent but a unit, like "eb"
+	}
+	var exp int
+	for ; end < len(s) && s[end] >= '0' && s[end] <= '9'; end++ {
+		if exp < 1000 { // Any larger exponent over- or underflows anyway
+			exp = exp*10 + int(s[end]-'0')
+		}
+	}
+	if end < len(s) && (s[end] == '.' || s[end] == ',') {
+		return nil, s, s[:end+1], ErrSyntax // Fractional exponent, like in "1e3.5MB"
+	}
+	if negExp {
+		exp = -exp
+	}
+
+	// The result has at most 19 integer and 19 fraction digits.
+	// Any further significant digits cannot affect the parsed size,
+	// and are therefore dropped, such that digits fits on the stack.
+	var digitBuf [40]byte
+	var nd int    // Number of significant digits
+	var point int // Position of the decimal point within digits
+	var dot bool
+	for i := 0; i < n; i++ {
+		switch c := s[i]; {
+		case c == '.' && !dot:
+			dot = true
+		case c >= '0' && c <= '9':
+			if nd == 0 && c == '0' {
+				if dot {
+					point--
 				}
-				return s, nil
+				continue // Skip leading zeros
 			}
-		} else {
-			switch {
-			case c >= '0' && c <= '9':
-				m = m*10 + uint64(c-'0')
-			case c == '.':
-				dot = true
-			default:
-				if i == 0 {
-					return 0, errors.New("mem: invalid size '" + orig + "'")
-				}
-				unit, ok := sizeUnits[s[i:]]
-				if !ok {
-					return 0, errors.New("mem: invalid size '" + orig + "'")
-				}
-				if neg {
-					if m > 1<<63/uint64(unit) {
-						return 0, errors.New("mem: invalid size '" + orig + "'")
-					}
-					return -1 * Size(m) * unit, nil
-				}
-				if m > math.MaxInt64/uint64(unit) {
-					return 0, errors.New("mem: invalid size '" + orig + "'")
-				}
-				return Size(m) * unit, nil
+			if nd < len(digitBuf) {
+				digitBuf[nd] = c
 			}
+			nd++
+			if !dot {
+				point++
+			}
+		default:
+			return nil, s, s[:end], ErrSyntax
+		}
+	}
+	if n == 0 || (n == 1 && dot) {
+		return nil, s, s[:end], ErrSyntax // No digits, like in ".e3MB"
+	}
+	point += exp
+	digits := digitBuf[:]
+	if nd < len(digitBuf) {
+		digits = digitBuf[:nd]
+	}
+
+	switch {
+	case len(digits) == 0 || point < -19: // Beyond the precision of a fraction
+		dst = append(dst, '0')
+	case point > 19:
+		return nil, s, s[:end], ErrRange
+	case point <= 0:
+		dst = append(dst, "0."...)
+		for ; point < 0; point++ {
+			dst = append(dst, '0')
+		}
+		dst = append(dst, digits...)
+	case point >= len(digits):
+		dst = append(dst, digits...)
+		for i := len(digits); i < point; i++ {
+			dst = append(dst, '0')
 		}
+	default:
+		dst = append(dst, digits[:point]...)
+		dst = append(dst, '.')
+		dst = append(dst, digits[point:]...)
 	}
-	return 0, errors.New("mem: invalid size '" + orig + "'")
+	return dst, s[end:], s[:0], nil
 }
 
-// ParseBitSize parses a bit size string. A bit size string
-// is a possibly signed decimal number with an optional
-// fraction and a unit suffix, such as "64Kbit" or "1mbit".
+// unitSuffix returns the unit suffix s with a single leading
+// space or tab, that separates the number from the unit, removed.
+func unitSuffix[T string | []byte](s T) T {
+	if len(s) > 0 && (s[0] == ' ' || s[0] == '\t') {
+		return s[1:]
+	}
+	return s
+}
+
+// ParseSizeStrict parses a size string like ParseSize but only
+// accepts the canonical form of a number. In particular, it rejects
+// superfluous leading zeros, like in "007KB", and numbers without
+// digits before or after the decimal point, like in "-.5KB" or
+// "5.KB". Such strings are accepted by ParseSize.
 //
-// A string may be a decimal size representation. Valid units
-// are "bit", "kbit", "mbit", "gbit" and "tbit".
-func ParseBitSize(s string) (BitSize, error) {
+// ParseSizeStrict is intended for linting configuration files. If s
+// is not canonical, it returns a *ParseError that wraps ErrSyntax
+// and records the non-canonical number as Part.
+func ParseSizeStrict(s string) (Size, error) {
+	num := s
+	if len(num) > 0 && (num[0] == '+' || num[0] == '-') {
+		num = num[1:]
+	}
+	if i := strings.IndexFunc(num, func(c rune) bool { return (c < '0' || c > '9') && c != '.' && c != ',' }); i >= 0 {
+		num = num[:i]
+	}
+
+	// Reject missing digits before or after the decimal point
+	// and superfluous leading zeros.
+	integer, fraction, dot := strings.Cut(num, ".")
+	if integer == "" || (dot && fraction == "") || (len(integer) > 1 && integer[0] == '0') {
+		return 0, &ParseError{Type: "size", Input: s, Part: num, Err: ErrSyntax}
+	}
+	return ParseSize(s)
+}
+
+// ParseSizeWith parses a size string like ParseSize but uses the
+// given units instead of the standard units. The units map unit
+// suffixes, which are matched exactly, to their sizes. For example:
+//
+//	units := map[string]mem.Size{"blk": 4 * mem.KiB, "B": mem.Byte}
+//	s, err := mem.ParseSizeWith("1.5blk", units) // 6KiB
+//
+// Units that are not greater than zero are ignored.
+func ParseSizeWith(s string, units map[string]Size) (Size, error) {
+	return parseSize(s, func(u string) (Size, bool) {
+		unit, ok := units[u]
+		return unit, ok && unit > 0
+	})
+}
+
+// ParseSizeTrim parses a size string like ParseSize but ignores
+// any leading and trailing whitespace, such as in " 5MB\n". It is
+// a shorthand for ParseSize(strings.TrimSpace(s)).
+func ParseSizeTrim(s string) (Size, error) {
+	return ParseSize(strings.TrimSpace(s))
+}
+
+// ParseSizeFlexible parses a size string like ParseSize but also
+// accepts some non-standard forms that are common in third-party
+// logs. In particular, it ignores leading and trailing whitespace
+// and accepts the unit in front of the number. For example:
+//
+//	" 5MB", "5MB\n", "MB5", "B1024", "KiB -1.5"
+//
+// The number and unit must still be valid according to ParseSize.
+// If s is not a valid size string, ParseSizeFlexible returns a
+// *ParseError like ParseSize.
+func ParseSizeFlexible(s string) (Size, error) {
 	orig := s
+	s = strings.TrimSpace(s)
 	if s == "" {
-		return 0, errors.New("mem: invalid bit size '" + orig + "'")
+		return 0, &ParseError{Type: "size", Input: orig, Err: ErrSyntax}
+	}
+
+	if c := s[0]; (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		i := strings.IndexFunc(s, func(c rune) bool {
+			return (c >= '0' && c <= '9') || c == '+' || c == '-' || c == '.'
+		})
+		if i < 0 {
+			return 0, &ParseError{Type: "size", Input: orig, Part: s, Err: ErrSyntax}
+		}
+		unit, num := strings.TrimSpace(s[:i]), s[i:]
+		s = num + unit
+	}
+	size, err := ParseSize(s)
+	if err != nil {
+		if e, ok := err.(*ParseError); ok {
+			e.Input = orig
+		}
+		return 0, err
+	}
+	return size, nil
+}
+
+// ParseSizes parses a comma-separated list of size strings,
+// such as "1MB, 2MB, 4MB". Each element may be surrounded by
+// whitespace and is parsed using ParseSize.
+//
+// If an element is not a valid size string, ParseSizes returns
+// an error that reports the position of the invalid element.
+// Since commas separate the elements, the elements must not contain
+// any thousands separators.
+func ParseSizes(s string) ([]Size, error) {
+	elems := strings.Split(s, ",")
+	sizes := make([]Size, 0, len(elems))
+	for i, elem := range elems {
+		size, err := ParseSize(strings.TrimSpace(elem))
+		if err != nil {
+			return nil, fmt.Errorf("%w at list index %d", err, i)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// ParseSizeFraction parses a size string like ParseSize but also
+// accepts a fraction of two integers in front of the unit, such as
+// "1/2GB" or "-3/4KiB". The resulting size is rounded to the nearest
+// byte. Halfway values are rounded away from zero.
+//
+// The numerator may be signed while the denominator must be a
+// positive integer. In particular, fractions with decimal points,
+// like "1.5/2GB", are rejected.
+//
+// If s is not a valid size string, ParseSizeFraction returns a
+// *ParseError that wraps ErrSyntax, or ErrRange if the size does
+// not fit into a Size.
+func ParseSizeFraction(s string) (Size, error) {
+	i := strings.IndexByte(s, '/')
+	if i < 0 {
+		return ParseSize(s)
 	}
 
+	num, rest := s[:i], s[i+1:]
 	var neg bool
-	if c := s[0]; c == '+' || c == '-' {
-		neg = c == '-'
-		s = s[1:]
+	if len(num) > 0 && (num[0] == '+' || num[0] == '-') {
+		neg = num[0] == '-'
+		num = num[1:]
 	}
+	j := 0
+	for j < len(rest) && rest[j] >= '0' && rest[j] <= '9' {
+		j++
+	}
+	den, unitStr := rest[:j], rest[j:]
 
-	var dot bool
-	var m, r uint64
-	var l uint64 = 1
-	for i, c := range s {
-		if dot {
-			switch {
-			case c >= '0' && c <= '9':
-				r = r*10 + uint64(c-'0')
-				l *= 10
-			default:
-				unit, ok := bitsizeUnits[s[i:]]
-				if !ok {
-					return 0, errors.New("mem: invalid size '" + orig + "'")
-				}
-				R := uint64(float64(r) / float64(l) * float64(unit))
+	n, err := parseDigits(num)
+	if err != nil {
+		return 0, &ParseError{Type: "size", Input: s, Part: num, Err: numError(err)}
+	}
+	d, err := parseDigits(den)
+	if err != nil {
+		return 0, &ParseError{Type: "size", Input: s, Part: den, Err: numError(err)}
+	}
+	if d == 0 {
+		return 0, &ParseError{Type: "size", Input: s, Part: den, Err: ErrSyntax}
+	}
+	unit, ok := sizeUnit(unitStr)
+	if !ok {
+		return 0, &ParseError{Type: "size", Input: s, Part: unitStr, Err: ErrSyntax}
+	}
 
-				if neg {
-					if m > 1<<63/uint64(unit) {
-						return 0, errors.New("mem: invalid size '" + orig + "'")
-					}
-					return -1 * (BitSize(m)*unit + BitSize(R)), nil
-				}
-				if m > math.MaxInt64/uint64(unit) {
-					return 0, errors.New("mem: invalid size '" + orig + "'")
-				}
+	hi, lo := bits.Mul64(n, uint64(unit))
+	if hi >= d {
+		return 0, &ParseError{Type: "size", Input: s, Part: s[:i+1+j], Err: ErrRange}
+	}
+	q, r := bits.Div64(hi, lo, d)
+	if r >= d-r {
+		if q == math.MaxUint64 {
+			return 0, &ParseError{Type: "size", Input: s, Part: s[:i+1+j], Err: ErrRange}
+		}
+		q++ // Round halfway values away from zero
+	}
+	switch {
+	case neg && q <= 1<<63:
+		return Size(-q), nil
+	case !neg && q <= math.MaxInt64:
+		return Size(q), nil
+	default:
+		return 0, &ParseError{Type: "size", Input: s, Part: s[:i+1+j], Err: ErrRange}
+	}
+}
 
-				s := BitSize(m)*unit + BitSize(R)
-				if s == math.MinInt64 {
-					return math.MaxInt64, nil
-				}
-				return s, nil
-			}
-		} else {
-			switch {
-			case c >= '0' && c <= '9':
-				m = m*10 + uint64(c-'0')
-			case c == '.':
-				dot = true
-			default:
-				if i == 0 {
-					return 0, errors.New("mem: invalid size '" + orig + "'")
-				}
-				unit, ok := bitsizeUnits[s[i:]]
-				if !ok {
-					return 0, errors.New("mem: invalid size '" + orig + "'")
-				}
-				if neg {
-					if m > 1<<63/uint64(unit) {
-						return 0, errors.New("mem: invalid size '" + orig + "'")
-					}
-					return -1 * BitSize(m) * unit, nil
-				}
-				if m > math.MaxInt64/uint64(unit) {
-					return 0, errors.New("mem: invalid size '" + orig + "'")
-				}
-				return BitSize(m) * unit, nil
-			}
+// numError returns ErrRange if err is a strconv.ErrRange
+// error and ErrSyntax otherwise.
+func numError(err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return ErrRange
+	}
+	return ErrSyntax
+}
+
+// parseDigits parses s as unsigned decimal integer. In contrast
+// to strconv.ParseUint, it rejects any sign and the empty string.
+func parseDigits(s string) (uint64, error) {
+	if len(s) == 0 || s[0] < '0' || s[0] > '9' {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// ParseSizeQuery parses a size string like ParseSize but also
+// accepts a plain decimal number of bytes without a unit, like
+// "1610612736", as returned by Size.Query. It is intended for
+// parsing sizes from URL query parameters.
+func ParseSizeQuery(s string) (Size, error) {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err == nil {
+		return Size(v), nil
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, &ParseError{Type: "size", Input: s, Part: s, Err: ErrRange}
+	}
+	return ParseSize(s)
+}
+
+// SizeFromEnv parses the value of the environment variable named
+// by key as size string, such as "5MiB", using ParseSize. If the
+// variable is not set or empty, SizeFromEnv returns def. For example:
+//
+//	maxBody, err := mem.SizeFromEnv("MAX_BODY", 5*mem.MiB)
+//
+// If the value is not a valid size string, the returned error
+// contains the name of the environment variable.
+func SizeFromEnv(key string, def Size) (Size, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def, nil
+	}
+	s, err := ParseSize(v)
+	if err != nil {
+		return 0, fmt.Errorf("%w in environment variable %s", err, key)
+	}
+	return s, nil
+}
+
+// ParseNetSize parses a size string that follows the networking
+// convention where a lowercase 'b' suffix denotes bits and an
+// uppercase 'B' suffix denotes bytes. For example, "100Mb" are
+// 100 megabits while "100MB" are 100 megabytes. The result is
+// returned as number of bits.
+//
+// Valid units are "b" and "B" with an optional decimal prefix
+// "k" or "K", "M", "G", "T", "P" or binary prefix "Ki", "Mi",
+// "Gi", "Ti", "Pi". In contrast to ParseSize, the case of the
+// unit matters. For example, "mb" and "MIB" are rejected.
+func ParseNetSize(s string) (BitSize, error) {
+	v, err := parseSize(s, netUnit)
+	if err != nil {
+		if e, ok := err.(*ParseError); ok {
+			e.Type = "bit size"
+		}
+		return 0, err
+	}
+	return BitSize(v), nil
+}
+
+// netUnit returns the number of bits of the networking unit s,
+// like "Mb" or "MB", as Size. It is used by ParseNetSize to parse
+// bits as Size.
+func netUnit(s string) (Size, bool) {
+	if len(s) == 0 {
+		return 0, false
+	}
+
+	var bits Size
+	switch s[len(s)-1] {
+	case 'b':
+		bits = 1
+	case 'B':
+		bits = 8
+	default:
+		return 0, false
+	}
+	switch s[:len(s)-1] {
+	case "":
+		return bits, true
+	case "k", "K":
+		return bits * 1000, true
+	case "M":
+		return bits * 1000 * 1000, true
+	case "G":
+		return bits * 1000 * 1000 * 1000, true
+	case "T":
+		return bits * 1000 * 1000 * 1000 * 1000, true
+	case "P":
+		return bits * 1000 * 1000 * 1000 * 1000 * 1000, true
+	case "Ki":
+		return bits << 10, true
+	case "Mi":
+		return bits << 20, true
+	case "Gi":
+		return bits << 30, true
+	case "Ti":
+		return bits << 40, true
+	case "Pi":
+		return bits << 50, true
+	default:
+		return 0, false
+	}
+}
+
+// ParseBandwidth parses a bandwidth string. A bandwidth string is
+// a possibly signed decimal number with an optional fraction and a
+// unit suffix followed by "/s", such as "1.25MB/s" or "100Mbit/s".
+//
+// Valid units are the byte units accepted by ParseSize, like "MB"
+// or "MiB", and the decimal bit units accepted by ParseBitSize, like
+// "Mbit", except for "nibble". Bit rates are converted to the internal bit
+// representation exactly. Fractions are handled like by ParseSize.
+func ParseBandwidth(s string) (Bandwidth, error) {
+	v, err := parseSize(s, bandwidthUnit)
+	if err != nil {
+		if e, ok := err.(*ParseError); ok {
+			e.Type = "bandwidth"
+		}
+		return 0, err
+	}
+	return Bandwidth(v), nil
+}
+
+// bandwidthUnit returns the number of bits per second of the
+// bandwidth unit s, like "MB/s" or "Mbit/s", as Size. It is used by
+// ParseBandwidth to parse bits per second as Size.
+func bandwidthUnit(s string) (Size, bool) {
+	if !strings.HasSuffix(s, "/s") {
+		return 0, false
+	}
+	s = s[:len(s)-2]
+	if bits, ok := bitsizeUnit(s); ok && bits != Nibble {
+		return Size(bits), true
+	}
+	unit, ok := sizeUnit(s)
+	if !ok || unit > math.MaxInt64/Size(BytePerSecond) { // 1EiB/s overflows
+		return 0, false
+	}
+	return unit * Size(BytePerSecond), true
+}
+
+// ParseBitSizeTrim parses a bit size string like ParseBitSize but
+// ignores any leading and trailing whitespace, such as in " 8Mbit\n".
+// It is a shorthand for ParseBitSize(strings.TrimSpace(s)).
+func ParseBitSizeTrim(s string) (BitSize, error) {
+	return ParseBitSize(strings.TrimSpace(s))
+}
+
+// ParseBitSize parses a bit size string. A bit size string
+// is a possibly signed decimal number with an optional
+// fraction and a unit suffix, such as "64Kbit" or "1mbit".
+//
+// A string may be a decimal size representation. Valid units
+// are "bit", "kbit", "mbit", "gbit", "tbit", "pbit" and "ebit".
+// In addition, "nibble" (4 bits) is accepted as unit.
+//
+// Like for ParseSize, the number may have an exponent, such as
+// "1.5e3Mbit". In contrast to ParseSize, ParseBitSize rejects
+// thousands separators, like in "1,000bit", and whitespace between
+// the number and the unit, like in "5 Mbit".
+//
+// If s is not a valid bit size string, ParseBitSize returns a
+// *ParseError that wraps ErrSyntax, or ErrRange if the bit size
+// does not fit into a BitSize.
+func ParseBitSize(s string) (BitSize, error) {
+	if i := strings.IndexAny(s, ", \t"); i >= 0 {
+		return 0, &ParseError{Type: "bit size", Input: s, Part: s[i:], Err: ErrSyntax}
+	}
+	v, err := parseSize(s, bitUnit)
+	if err != nil {
+		if e, ok := err.(*ParseError); ok {
+			e.Type = "bit size"
 		}
+		return 0, err
 	}
-	return 0, errors.New("mem: invalid size '" + orig + "'")
+	return BitSize(v), nil
+}
+
+// bitUnit returns the number of bits of the bit size unit s, like
+// "Mbit" or "nibble", as Size. It is used by ParseBitSize to parse
+// bits as Size.
+func bitUnit(s string) (Size, bool) {
+	bits, ok := bitsizeUnit(s)
+	return Size(bits), ok
 }
 
 // FormatSize converts the size s to a string, according to the
@@ -188,67 +717,227 @@ func ParseBitSize(s string) (BitSize, error) {
 // The precision prec controls the number of digits after the decimal
 // point printed by the 'd' and 'b' formats. The special precision
 // -1 uses the smallest number of digits necessary such that ParseSize
-// will return s exactly.
+// will return s exactly. Any precision < -1 is treated as -1.
 func FormatSize(s Size, fmt byte, prec int) string {
+	var buf [24]byte
+	return string(AppendSize(buf[:0], s, fmt, prec))
+}
+
+// AppendSize appends the size s, formatted according to the format
+// fmt and precision prec like by FormatSize, to dst and returns the
+// extended buffer. It does not allocate if dst has enough capacity.
+func AppendSize(dst []byte, s Size, fmt byte, prec int) []byte {
 	if s == 0 { // Optimized path for the zero value
 		switch fmt {
 		case 'd', 'b':
-			return "0b"
+			return append(dst, "0b"...)
 		case 'D', 'B':
-			return "0B"
+			return append(dst, "0B"...)
 		default:
-			return string([]byte{'%', fmt})
+			return append(dst, '%', fmt)
 		}
 	}
 
 	switch fmt {
 	case 'd', 'D':
-		var p, t, g, m, k, b string
+		var e, p, t, g, m, k, b string
 		if fmt == 'D' {
-			p, t, g, m, k, b = "PB", "TB", "GB", "MB", "KB", "B"
+			e, p, t, g, m, k, b = "EB", "PB", "TB", "GB", "MB", "KB", "B"
 		} else {
-			p, t, g, m, k, b = "pb", "tb", "gb", "mb", "kb", "b"
+			e, p, t, g, m, k, b = "eb", "pb", "tb", "gb", "mb", "kb", "b"
 		}
 		switch {
+		case s >= EB || s <= -EB:
+			return appendNum(dst, int64(s), int64(EB), prec, e)
 		case s >= PB || s <= -PB:
-			return string(fmtNum(int64(s), int64(PB), prec, p))
+			return appendNum(dst, int64(s), int64(PB), prec, p)
 		case s >= TB || s <= -TB:
-			return string(fmtNum(int64(s), int64(TB), prec, t))
+			return appendNum(dst, int64(s), int64(TB), prec, t)
 		case s >= GB || s <= -GB:
-			return string(fmtNum(int64(s), int64(GB), prec, g))
+			return appendNum(dst, int64(s), int64(GB), prec, g)
 		case s >= MB || s <= -MB:
-			return string(fmtNum(int64(s), int64(MB), prec, m))
+			return appendNum(dst, int64(s), int64(MB), prec, m)
 		case s >= KB || s <= -KB:
-			return string(fmtNum(int64(s), int64(KB), prec, k))
+			return appendNum(dst, int64(s), int64(KB), prec, k)
 		default:
-			return string(fmtNum(int64(s), int64(Byte), prec, b))
+			return appendNum(dst, int64(s), int64(Byte), prec, b)
 		}
 	case 'b', 'B':
-		var p, t, g, m, k, b string
+		var e, p, t, g, m, k, b string
 		if fmt == 'B' {
-			p, t, g, m, k, b = "PiB", "TiB", "GiB", "MiB", "KiB", "B"
+			e, p, t, g, m, k, b = "EiB", "PiB", "TiB", "GiB", "MiB", "KiB", "B"
 		} else {
-			p, t, g, m, k, b = "pib", "tib", "gib", "mib", "kib", "b"
+			e, p, t, g, m, k, b = "eib", "pib", "tib", "gib", "mib", "kib", "b"
 		}
 		switch {
+		case s >= EiB || s <= -EiB:
+			return appendNum(dst, int64(s), int64(EiB), prec, e)
 		case s >= PiB || s <= -PiB:
-			return string(fmtNum(int64(s), int64(PiB), prec, p))
+			return appendNum(dst, int64(s), int64(PiB), prec, p)
 		case s >= TiB || s <= -TiB:
-			return string(fmtNum(int64(s), int64(TiB), prec, t))
+			return appendNum(dst, int64(s), int64(TiB), prec, t)
 		case s >= GiB || s <= -GiB:
-			return string(fmtNum(int64(s), int64(GiB), prec, g))
+			return appendNum(dst, int64(s), int64(GiB), prec, g)
 		case s >= MiB || s <= -MiB:
-			return string(fmtNum(int64(s), int64(MiB), prec, m))
+			return appendNum(dst, int64(s), int64(MiB), prec, m)
 		case s >= KiB || s <= -KiB:
-			return string(fmtNum(int64(s), int64(KiB), prec, k))
+			return appendNum(dst, int64(s), int64(KiB), prec, k)
 		default:
-			return string(fmtNum(int64(s), int64(Byte), prec, b))
+			return appendNum(dst, int64(s), int64(Byte), prec, b)
 		}
 	default:
-		return string([]byte{'%', fmt})
+		return append(dst, '%', fmt)
 	}
 }
 
+// ValidFormat reports whether fmt is a valid format for FormatSize,
+// i.e. one of 'd', 'D', 'b' or 'B'. FormatSize formats a size with
+// an invalid format as "%" followed by the format, like "%x".
+func ValidFormat(fmt byte) bool {
+	switch fmt {
+	case 'd', 'D', 'b', 'B':
+		return true
+	default:
+		return false
+	}
+}
+
+// CheckFormat returns an error if fmt is not a valid format for
+// FormatSize or if prec is smaller than -1. It can be used to
+// validate a user-provided format and precision, for example when
+// loading a configuration file, before formatting any size.
+//
+// Although FormatSize treats any precision < -1 as -1, CheckFormat
+// rejects such precisions since they usually indicate a mistake.
+func CheckFormat(fmt byte, prec int) error {
+	if !ValidFormat(fmt) {
+		return errors.New("mem: invalid format '" + string(rune(fmt)) + "'")
+	}
+	if prec < -1 {
+		return errors.New("mem: invalid precision " + strconv.Itoa(prec))
+	}
+	return nil
+}
+
+// FormatOptions controls optional aspects of formatting
+// a size with FormatSizeWith.
+type FormatOptions struct {
+	// Compact removes any trailing zeros after the decimal
+	// point, and the decimal point itself if no digits remain.
+	// Hence, the precision becomes the max. number of digits
+	// after the decimal point. For example, 2.5GB formatted
+	// with precision 4 is "2.5GB" instead of "2.5000GB".
+	Compact bool
+
+	// MinUnit, if set, is the smallest unit used to format
+	// a size. Sizes smaller than MinUnit are formatted as
+	// fraction of it while larger sizes are formatted with
+	// their usual unit. For example, with MinUnit KB, 500
+	// bytes are formatted as "0.5KB" instead of "500B".
+	//
+	// MinUnit must be a unit of the format, like KB or MB
+	// for 'd' and 'D' or KiB or MiB for 'b' and 'B'.
+	// Otherwise, it is ignored.
+	MinUnit Size
+
+	// Signed prefixes positive sizes with a '+' sign, such
+	// that the sign is always shown, like in "+250MB" or
+	// "-1.2GB". The zero size is formatted without a sign.
+	// This is useful when displaying changes or deltas.
+	Signed bool
+}
+
+// FormatSizeWith converts the size s to a string, according to the
+// format fmt, precision prec and the options opts. With the zero
+// FormatOptions, it is equivalent to FormatSize.
+func FormatSizeWith(s Size, fmt byte, prec int, opts FormatOptions) string {
+	var buf [24]byte
+	var b []byte
+	if unit := sizeUnitString(opts.MinUnit, fmt); unit != "" && s > -opts.MinUnit && s < opts.MinUnit {
+		b = appendNum(buf[:0], int64(s), int64(opts.MinUnit), prec, unit)
+	} else {
+		b = AppendSize(buf[:0], s, fmt, prec)
+	}
+	if opts.Compact {
+		b = compactNum(b)
+	}
+	if opts.Signed && s > 0 && b[0] != '%' {
+		b = append(b, 0)
+		copy(b[1:], b)
+		b[0] = '+'
+	}
+	return string(b)
+}
+
+// FormatSizeAligned converts the size s to a string of at least
+// width characters that lines up with other sizes formatted with the
+// same width, for example when printing a table:
+//
+//	"  1.5GB"
+//	"234.0MB"
+//	" 12.0B "
+//
+// The size is formatted using decimal units with one digit after the
+// decimal point. The number is right-aligned and the unit is
+// left-aligned and padded to two characters. FormatSizeAligned never
+// truncates the formatted size, and therefore, the resulting string
+// may be longer than width.
+func FormatSizeAligned(s Size, width int) string {
+	const unitWidth = 2 // The longest 'D' units, like "MB", are 2 characters long
+
+	var buf [24]byte
+	var b []byte
+	if s == 0 { // AppendSize formats zero as "0B" regardless of the precision
+		b = append(buf[:0], "0.0B"...)
+	} else {
+		b = AppendSize(buf[:0], s, 'D', 1)
+	}
+	n := len(b) - 1
+	for n > 0 && (b[n-1] < '0' || b[n-1] > '9') {
+		n--
+	}
+	num, unit := b[:n], b[n:]
+
+	out := make([]byte, 0, width)
+	for i := len(num) + unitWidth; i < width; i++ {
+		out = append(out, ' ')
+	}
+	out = append(out, num...)
+	out = append(out, unit...)
+	for i := len(unit); i < unitWidth; i++ {
+		out = append(out, ' ')
+	}
+	return string(out)
+}
+
+// FormatSizeIn converts the size s to a string using the given unit
+// instead of the unit that matches the magnitude of s. For example,
+// 1.5GB in MiB with precision 1 is "1430.5MiB". This is useful for
+// tables where all sizes should be shown in the same unit.
+//
+// The unit must be one of the decimal or binary units, like KB or
+// MiB. Otherwise, FormatSizeIn selects the unit automatically, like
+// FormatSize with the decimal 'D' resp. 'd' format. The precision
+// prec is interpreted like by FormatSize. If upper is true, the
+// unit is partially uppercase, like "MiB", instead of lowercase,
+// like "mib".
+func FormatSizeIn(s Size, unit Size, prec int, upper bool) string {
+	fmt := byte('d')
+	if unit != Byte && sizeUnitString(unit, 'b') != "" {
+		fmt = 'b'
+	}
+	if upper {
+		fmt -= 'a' - 'A'
+	}
+
+	var buf [32]byte
+	label := sizeUnitString(unit, fmt)
+	if label == "" {
+		return string(AppendSize(buf[:0], s, fmt, prec))
+	}
+	return string(appendNum(buf[:0], int64(s), int64(unit), prec, label))
+}
+
 // FormatBitSize converts the bit size s to a string, according to the
 // format fmt and precision prec.
 //
@@ -256,122 +945,767 @@ func FormatSize(s Size, fmt byte, prec int) string {
 // are:
 //   - 'd' formats s as "-ddd.dddddmbit" using the decimal byte units.
 //   - 'D' formats s as "-ddd.dddddMbit" using the decimal byte units.
+//   - 'n' formats s as "-ddd.dddddnibble" using nibbles (4 bits).
+//   - 'N' formats s as "-ddd.dddddNibble" using nibbles (4 bits).
 //
 // The precision prec controls the number of digits after the decimal
-// point printed by the 'd' and 'D' formats. The special precision
-// -1 uses the smallest number of digits necessary such that ParseBitSize
-// will return s exactly.
+// point printed by the 'd', 'D', 'n' and 'N' formats. The special
+// precision -1 uses the smallest number of digits necessary such that
+// ParseBitSize will return s exactly. Any precision < -1 is treated
+// as -1.
 func FormatBitSize(s BitSize, fmt byte, prec int) string {
+	var buf [24]byte
+	return string(AppendBitSize(buf[:0], s, fmt, prec))
+}
+
+// AppendBitSize appends the bit size s, formatted according to the
+// format fmt and precision prec like by FormatBitSize, to dst and
+// returns the extended buffer. It does not allocate if dst has
+// enough capacity.
+func AppendBitSize(dst []byte, s BitSize, fmt byte, prec int) []byte {
 	if s == 0 {
 		switch fmt {
 		case 'd':
-			return "0bit"
+			return append(dst, "0bit"...)
 		case 'D':
-			return "0Bit"
+			return append(dst, "0Bit"...)
+		case 'n':
+			return append(dst, "0nibble"...)
+		case 'N':
+			return append(dst, "0Nibble"...)
 		default:
-			return string([]byte{'%', fmt})
+			return append(dst, '%', fmt)
 		}
 	}
 
-	var t, g, m, k, b string
+	var e, p, t, g, m, k, b string
 	switch fmt {
 	case 'd':
-		t, g, m, k, b = "tbit", "gbit", "mbit", "kbit", "bit"
+		e, p, t, g, m, k, b = "ebit", "pbit", "tbit", "gbit", "mbit", "kbit", "bit"
 	case 'D':
-		t, g, m, k, b = "Tbit", "Gbit", "Mbit", "Kbit", "Bit"
+		e, p, t, g, m, k, b = "Ebit", "Pbit", "Tbit", "Gbit", "Mbit", "Kbit", "Bit"
+	case 'n':
+		return appendNum(dst, int64(s), int64(Nibble), prec, "nibble")
+	case 'N':
+		return appendNum(dst, int64(s), int64(Nibble), prec, "Nibble")
 	default:
-		return string([]byte{'%', fmt})
+		return append(dst, '%', fmt)
 	}
 	switch {
+	case s >= EBit || s <= -EBit:
+		return appendNum(dst, int64(s), int64(EBit), prec, e)
+	case s >= PBit || s <= -PBit:
+		return appendNum(dst, int64(s), int64(PBit), prec, p)
 	case s >= TBit || s <= -TBit:
-		return string(fmtNum(int64(s), int64(TBit), prec, t))
+		return appendNum(dst, int64(s), int64(TBit), prec, t)
 	case s >= GBit || s <= -GBit:
-		return string(fmtNum(int64(s), int64(GBit), prec, g))
+		return appendNum(dst, int64(s), int64(GBit), prec, g)
 	case s >= MBit || s <= -MBit:
-		return string(fmtNum(int64(s), int64(MBit), prec, m))
+		return appendNum(dst, int64(s), int64(MBit), prec, m)
 	case s >= KBit || s <= -KBit:
-		return string(fmtNum(int64(s), int64(KBit), prec, k))
+		return appendNum(dst, int64(s), int64(KBit), prec, k)
+	default:
+		return appendNum(dst, int64(s), int64(Bit), prec, b)
+	}
+}
+
+// FormatBitSizeIn converts the bit size s to a string using the
+// given unit, like Mbit or Nibble, instead of the unit that matches
+// the magnitude of s. It is the BitSize equivalent of FormatSizeIn.
+//
+// If unit is not one of the bit size units, FormatBitSizeIn selects
+// the unit automatically, like FormatBitSize with the decimal 'D'
+// resp. 'd' format. If upper is true, the unit is partially
+// uppercase, like "Mbit", instead of lowercase, like "mbit".
+func FormatBitSizeIn(s BitSize, unit BitSize, prec int, upper bool) string {
+	var label string
+	switch unit {
+	case Bit:
+		label = "bit"
+	case Nibble:
+		label = "nibble"
+	case KBit:
+		label = "kbit"
+	case MBit:
+		label = "mbit"
+	case GBit:
+		label = "gbit"
+	case TBit:
+		label = "tbit"
+	case PBit:
+		label = "pbit"
+	case EBit:
+		label = "ebit"
+	}
+
+	var buf [32]byte
+	if label == "" {
+		fmt := byte('d')
+		if upper {
+			fmt = 'D'
+		}
+		return string(AppendBitSize(buf[:0], s, fmt, prec))
+	}
+	if upper {
+		label = strings.ToUpper(label[:1]) + label[1:]
+	}
+	return string(appendNum(buf[:0], int64(s), int64(unit), prec, label))
+}
+
+// FormatBandwidth converts the bandwidth b to a string, according
+// to the format fmt and precision prec. The string always ends
+// with "/s".
+//
+// The format fmt specifies how to format the bandwidth b. Valid
+// values are:
+//   - 'd' formats b as "-ddd.dddddmb/s" using the decimal byte units.
+//   - 'D' formats b as "-ddd.dddddMB/s" using the decimal byte units.
+//   - 'b' formats b as "-ddd.dddddmib/s" using the binary byte units.
+//   - 'B' formats b as "-ddd.dddddMiB/s" using the binary byte units.
+//   - 't' formats b as "-ddd.dddddmbit/s" using the decimal bit units.
+//   - 'T' formats b as "-ddd.dddddMbit/s" using the decimal bit units.
+//
+// The precision prec controls the number of digits after the decimal
+// point. The special precision -1 uses the smallest number of digits
+// necessary such that ParseBandwidth will return b exactly. Any
+// precision < -1 is treated as -1.
+func FormatBandwidth(b Bandwidth, fmt byte, prec int) string {
+	var buf [24]byte
+	return string(appendBandwidth(buf[:0], b, fmt, prec))
+}
+
+// FormatBandwidthTC converts the bandwidth b to a string that
+// can be used as rate by the Linux traffic control (tc) utility,
+// such as "100mbit" or "1.5gbit".
+//
+// FormatBandwidthTC always uses the lowercase decimal bit units
+// "bit", "kbit", "mbit", "gbit", "tbit", "pbit" and "ebit" and
+// never the byte units, like "mbps", since tc interprets "bps"
+// as bytes - not bits - per second, which is a common source of
+// confusion.
+// The rate is formatted exactly without rounding.
+func FormatBandwidthTC(b Bandwidth) string {
+	var buf [24]byte
+	switch {
+	case b == 0:
+		return "0bit"
+	case b >= EBitPerSecond || b <= -EBitPerSecond:
+		return string(appendNum(buf[:0], int64(b), int64(EBitPerSecond), -1, "ebit"))
+	case b >= PBitPerSecond || b <= -PBitPerSecond:
+		return string(appendNum(buf[:0], int64(b), int64(PBitPerSecond), -1, "pbit"))
+	case b >= TBitPerSecond || b <= -TBitPerSecond:
+		return string(appendNum(buf[:0], int64(b), int64(TBitPerSecond), -1, "tbit"))
+	case b >= GBitPerSecond || b <= -GBitPerSecond:
+		return string(appendNum(buf[:0], int64(b), int64(GBitPerSecond), -1, "gbit"))
+	case b >= MBitPerSecond || b <= -MBitPerSecond:
+		return string(appendNum(buf[:0], int64(b), int64(MBitPerSecond), -1, "mbit"))
+	case b >= KBitPerSecond || b <= -KBitPerSecond:
+		return string(appendNum(buf[:0], int64(b), int64(KBitPerSecond), -1, "kbit"))
 	default:
-		return string(fmtNum(int64(s), int64(Bit), prec, b))
+		return string(appendNum(buf[:0], int64(b), int64(BitPerSecond), -1, "bit"))
+	}
+}
+
+// FormatRate converts the bandwidth of transferring the given amount
+// of data within the duration over to a string. It is equivalent to
+// formatting the resulting bandwidth using FormatBandwidth with the
+// given format fmt and precision prec.
+//
+// If over <= 0, the resulting bandwidth is zero.
+func FormatRate(transferred Size, over time.Duration, fmt byte, prec int) string {
+	return FormatBandwidth(Throughput(transferred, over), fmt, prec)
+}
+
+// FormatSizeDiff returns a string describing the change from the
+// size before to the size after, such as:
+//
+//	"before: 1.2GB, after: 980MB (-220MB, -18.3%)"
+//
+// The delta is always prefixed with its sign unless before and
+// after are equal. The percentage change is relative to before
+// and omitted if before is zero.
+func FormatSizeDiff(before, after Size) string {
+	delta, grew := after.Delta(before)
+
+	var sign string
+	switch {
+	case grew:
+		sign = "+"
+	case after < before:
+		sign = "-"
+	}
+
+	buf := make([]byte, 0, 48)
+	buf = append(buf, "before: "...)
+	buf = append(buf, before.String()...)
+	buf = append(buf, ", after: "...)
+	buf = append(buf, after.String()...)
+	buf = append(buf, " ("...)
+	buf = append(buf, sign...)
+	buf = append(buf, delta.String()...)
+	if before != 0 {
+		p := (float64(after) - float64(before)) / math.Abs(float64(before)) * 100
+		buf = append(buf, ", "...)
+		if p > 0 {
+			buf = append(buf, '+')
+		}
+		buf = strconv.AppendFloat(buf, p, 'f', 1, 64)
+		buf = append(buf, '%')
+	}
+	buf = append(buf, ')')
+	return string(buf)
+}
+
+// FormatProgress returns a string describing the progress of an
+// operation that has processed done out of total bytes, such as:
+//
+//	"1.5GB / 4GB (37.5%)"
+//
+// The percentage is clamped to [0, 100]. If total <= 0, the
+// percentage is 100.
+func FormatProgress(done, total Size) string {
+	buf := make([]byte, 0, 32)
+	buf = append(buf, done.String()...)
+	buf = append(buf, " / "...)
+	buf = append(buf, total.String()...)
+	buf = append(buf, " ("...)
+	buf = appendPercent(buf, done, total)
+	buf = append(buf, ')')
+	return string(buf)
+}
+
+// FormatRatio returns the ratio a/b in the form "3.2:1" with one
+// digit after the decimal point, as commonly used for compression
+// ratios. The ratio is computed by a.Ratio(b). If b is zero, the
+// ratio is undefined and FormatRatio returns "n/a".
+func FormatRatio(a, b Size) string {
+	if b == 0 {
+		return "n/a"
 	}
+	var buf [32]byte
+	r := strconv.AppendFloat(buf[:0], a.Ratio(b), 'f', 1, 64)
+	return string(append(r, ":1"...))
 }
 
-func fmtNum(v, base int64, prec int, unit string) []byte {
+// appendPercent appends the percentage of done out of total bytes
+// with one digit after the decimal point, such as "37.5%", to dst.
+// The percentage is clamped to [0, 100]. If total <= 0, the
+// percentage is 100.
+func appendPercent(dst []byte, done, total Size) []byte {
+	p := 100.0
+	if total > 0 {
+		p = float64(done) / float64(total) * 100
+		if p < 0 {
+			p = 0
+		}
+		if p > 100 {
+			p = 100
+		}
+	}
+	dst = strconv.AppendFloat(dst, p, 'f', 1, 64)
+	return append(dst, '%')
+}
+
+// FormatSizeReport returns a multi-line report of the given named
+// sizes followed by their total, similar to the output of du:
+//
+//	backups   1.25GB
+//	photos   12.50GB
+//	total    13.75GB
+//
+// The entries are sorted by name. All sizes are formatted with
+// the same decimal unit - the unit of the largest absolute size,
+// including the total - and two digits after the decimal point.
+// Names and sizes are aligned to the longest name resp. size.
+// Each line, including the last one, ends with a newline.
+//
+// The total is always the last line. An entry named "total" is
+// sorted among the other entries and does not replace the total.
+// Callers that need to tell them apart should use a different
+// name.
+//
+// The total saturates at the max. resp. min. representable Size.
+func FormatSizeReport(entries map[string]Size) string {
+	const totalName = "total"
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var total, max Size
+	nameWidth := len(totalName)
+	for _, name := range names {
+		s := entries[name]
+		total = Size(add(int64(total), int64(s)))
+		max = max.Max(s.Abs())
+		if len(name) > nameWidth {
+			nameWidth = len(name)
+		}
+	}
+	max = max.Max(total.Abs())
+
+	var (
+		base = Byte
+		unit = "B"
+		prec = 2
+	)
+	switch {
+	case max >= EB:
+		base, unit = EB, "EB"
+	case max >= PB:
+		base, unit = PB, "PB"
+	case max >= TB:
+		base, unit = TB, "TB"
+	case max >= GB:
+		base, unit = GB, "GB"
+	case max >= MB:
+		base, unit = MB, "MB"
+	case max >= KB:
+		base, unit = KB, "KB"
+	default:
+		prec = 0
+	}
+
+	names = append(names, totalName)
+	sizes := make([][]byte, 0, len(names))
+	sizeWidth := 0
+	for i, name := range names {
+		s := total
+		if i < len(names)-1 {
+			s = entries[name]
+		}
+		b := appendNum(nil, int64(s), int64(base), prec, unit)
+		if len(b) > sizeWidth {
+			sizeWidth = len(b)
+		}
+		sizes = append(sizes, b)
+	}
+
+	var buf strings.Builder
+	for i, name := range names {
+		buf.WriteString(name)
+		for j := len(name) + len(sizes[i]); j < nameWidth+2+sizeWidth; j++ {
+			buf.WriteByte(' ')
+		}
+		buf.Write(sizes[i])
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+func appendBandwidth(dst []byte, b Bandwidth, fmt byte, prec int) []byte {
+	if b == 0 {
+		switch fmt {
+		case 'd', 'b':
+			return append(dst, "0b/s"...)
+		case 'D', 'B':
+			return append(dst, "0B/s"...)
+		case 't', 'T':
+			return append(dst, "0bit/s"...)
+		default:
+			return append(dst, '%', fmt)
+		}
+	}
+
+	// There is no exbibyte tier since 1EiB/s exceeds the largest
+	// Bandwidth.
+	var e, p, t, g, m, k, u string
+	switch fmt {
+	case 'd':
+		e, p, t, g, m, k, u = "eb/s", "pb/s", "tb/s", "gb/s", "mb/s", "kb/s", "b/s"
+	case 'D':
+		e, p, t, g, m, k, u = "EB/s", "PB/s", "TB/s", "GB/s", "MB/s", "KB/s", "B/s"
+	case 'b':
+		p, t, g, m, k, u = "pib/s", "tib/s", "gib/s", "mib/s", "kib/s", "b/s"
+	case 'B':
+		p, t, g, m, k, u = "PiB/s", "TiB/s", "GiB/s", "MiB/s", "KiB/s", "B/s"
+	case 't':
+		e, p, t, g, m, k, u = "ebit/s", "pbit/s", "tbit/s", "gbit/s", "mbit/s", "kbit/s", "bit/s"
+	case 'T':
+		e, p, t, g, m, k, u = "Ebit/s", "Pbit/s", "Tbit/s", "Gbit/s", "Mbit/s", "Kbit/s", "bit/s"
+	default:
+		return append(dst, '%', fmt)
+	}
+
+	switch fmt {
+	case 'b', 'B':
+		switch {
+		case b >= PiBytePerSecond || b <= -PiBytePerSecond:
+			return appendNum(dst, int64(b), int64(PiBytePerSecond), prec, p)
+		case b >= TiBytePerSecond || b <= -TiBytePerSecond:
+			return appendNum(dst, int64(b), int64(TiBytePerSecond), prec, t)
+		case b >= GiBytePerSecond || b <= -GiBytePerSecond:
+			return appendNum(dst, int64(b), int64(GiBytePerSecond), prec, g)
+		case b >= MiBytePerSecond || b <= -MiBytePerSecond:
+			return appendNum(dst, int64(b), int64(MiBytePerSecond), prec, m)
+		case b >= KiBytePerSecond || b <= -KiBytePerSecond:
+			return appendNum(dst, int64(b), int64(KiBytePerSecond), prec, k)
+		default:
+			return appendNum(dst, int64(b), int64(BytePerSecond), prec, u)
+		}
+	case 't', 'T':
+		switch {
+		case b >= EBitPerSecond || b <= -EBitPerSecond:
+			return appendNum(dst, int64(b), int64(EBitPerSecond), prec, e)
+		case b >= PBitPerSecond || b <= -PBitPerSecond:
+			return appendNum(dst, int64(b), int64(PBitPerSecond), prec, p)
+		case b >= TBitPerSecond || b <= -TBitPerSecond:
+			return appendNum(dst, int64(b), int64(TBitPerSecond), prec, t)
+		case b >= GBitPerSecond || b <= -GBitPerSecond:
+			return appendNum(dst, int64(b), int64(GBitPerSecond), prec, g)
+		case b >= MBitPerSecond || b <= -MBitPerSecond:
+			return appendNum(dst, int64(b), int64(MBitPerSecond), prec, m)
+		case b >= KBitPerSecond || b <= -KBitPerSecond:
+			return appendNum(dst, int64(b), int64(KBitPerSecond), prec, k)
+		default:
+			return appendNum(dst, int64(b), int64(BitPerSecond), prec, u)
+		}
+	default:
+		switch {
+		case b >= EBytePerSecond || b <= -EBytePerSecond:
+			return appendNum(dst, int64(b), int64(EBytePerSecond), prec, e)
+		case b >= PBytePerSecond || b <= -PBytePerSecond:
+			return appendNum(dst, int64(b), int64(PBytePerSecond), prec, p)
+		case b >= TBytePerSecond || b <= -TBytePerSecond:
+			return appendNum(dst, int64(b), int64(TBytePerSecond), prec, t)
+		case b >= GBytePerSecond || b <= -GBytePerSecond:
+			return appendNum(dst, int64(b), int64(GBytePerSecond), prec, g)
+		case b >= MBytePerSecond || b <= -MBytePerSecond:
+			return appendNum(dst, int64(b), int64(MBytePerSecond), prec, m)
+		case b >= KBytePerSecond || b <= -KBytePerSecond:
+			return appendNum(dst, int64(b), int64(KBytePerSecond), prec, k)
+		default:
+			return appendNum(dst, int64(b), int64(BytePerSecond), prec, u)
+		}
+	}
+}
+
+// appendNum appends v as decimal number of base units with the
+// given precision prec, followed by the unit, to dst.
+func appendNum(dst []byte, v, base int64, prec int, unit string) []byte {
+	if prec < -1 {
+		prec = -1
+	}
 	m := v / base
 	r := v % base
 
-	// Usually a formatted string consists of
-	// a potential minus sign, at most three
-	// digits and any precision digits followed
-	// by the unit.
-	// For example: -999Tbit or 512.125GiB
-	//
-	// We don't optimize for edge cases
-	// like: 4096000Tbit
-	//
-	// Hence, we allocate an 4+prec+1+unit
-	// (+1 for the '.') buffer when r == 0.
-	//
-	// Otherwise, we format r first with the
-	// requested precision: 0.xyz...
-	// Then we concat and return the m and r
-	// buffers as: abc.xyz
-
-	var buf []byte
 	switch {
 	case r == 0 && prec <= 0:
-		buf = make([]byte, 0, 4+len(unit))
-		buf = strconv.AppendInt(buf, m, 10)
+		dst = strconv.AppendInt(dst, m, 10)
 	case r == 0:
-		buf = make([]byte, 0, 4+prec+1+len(unit))
-		buf = strconv.AppendInt(buf, m, 10)
-		buf = append(buf, '.')
+		dst = strconv.AppendInt(dst, m, 10)
+		dst = append(dst, '.')
 		for prec > 0 {
-			buf = append(buf, '0')
+			dst = append(dst, '0')
 			prec--
 		}
 	default:
 		if r < 0 {
 			r *= -1
 		}
-		rbuf := strconv.AppendFloat(nil, float64(r)/float64(base), 'f', prec, 64)
-		buf = make([]byte, 0, 4+prec+len(rbuf)+len(unit)) // The '.' is already included in rbuf
+
+		// We format r with the requested precision as .xyz...
+		// first and then concat m and r as: abc.xyz
+		var buf [32]byte
+		var frac []byte
+		if k := log10(base); k > 0 && (prec < 0 || prec >= k) {
+			// For decimal units, like KB or Mbit, the digits of r
+			// are the digits after the decimal point. Hence, we can
+			// format r without any floating point conversion unless
+			// we have to round r to fewer digits.
+			frac = buf[:k+1]
+			frac[0] = '.'
+			for i := k; i > 0; i-- {
+				frac[i] = '0' + byte(r%10)
+				r /= 10
+			}
+			if prec < 0 {
+				for frac[len(frac)-1] == '0' {
+					frac = frac[:len(frac)-1]
+				}
+			}
+			for i := k; i < prec; i++ {
+				frac = append(frac, '0')
+			}
+		} else if prec < 0 && base > 1<<53 {
+			// For large binary units, like EiB, a float64 cannot
+			// represent r / base precisely. Hence, we compute the
+			// shortest fraction that gets parsed as r exactly.
+			frac = appendExactFraction(buf[:0], uint64(r), uint64(base))
+		} else {
+			frac = strconv.AppendFloat(buf[:0], float64(r)/float64(base), 'f', prec, 64)
+			if frac[0] == '1' {
+				// r / base has been rounded up to 1. For example,
+				// when formatting 1999 bytes as KB with precision 2.
+				if v < 0 {
+					m--
+				} else {
+					m++
+				}
+			}
+			frac = frac[1:] // Remove the leading '0' or '1'
+		}
+
 		if v < 0 && m == 0 {
 			// When formatting a negative size like -4bit as -0.5b
 			// where the abs. value is small than the base,
 			// m = v / base will be zero.
 			// In this case, we have to add the minus sign manually
 			// since strconv.AppendInt(buf, 0, 10) will not add it.
-			buf = append(buf, '-')
+			dst = append(dst, '-')
 		}
-		buf = strconv.AppendInt(buf, m, 10)
-		buf = append(buf, rbuf[1:]...)
+		dst = strconv.AppendInt(dst, m, 10)
+		dst = append(dst, frac...)
 	}
-	buf = append(buf, unit...)
-	buf = buf[:len(buf):len(buf)]
-	return buf
+	return append(dst, unit...)
 }
 
-var sizeUnits = map[string]Size{
-	"b": Byte, "B": Byte,
+// writeFormatted writes the formatted value b to f. It
+// honors the width and the '-', '+' and ' ' flags of f. The
+// '+' and ' ' flags only apply to non-negative values.
+func writeFormatted(f fmt.State, b []byte, nonNegative bool) {
+	var sign string
+	switch {
+	case nonNegative && f.Flag('+'):
+		sign = "+"
+	case nonNegative && f.Flag(' '):
+		sign = " "
+	}
 
-	"kb": KB, "KB": KB,
-	"mb": MB, "MB": MB,
-	"gb": GB, "GB": GB,
-	"tb": TB, "TB": TB,
-	"pb": PB, "PB": PB,
+	var pad int
+	if width, ok := f.Width(); ok {
+		pad = width - len(sign) - len(b)
+	}
+	if !f.Flag('-') {
+		for ; pad > 0; pad-- {
+			io.WriteString(f, " ")
+		}
+	}
+	io.WriteString(f, sign)
+	f.Write(b)
+	for ; pad > 0; pad-- {
+		io.WriteString(f, " ")
+	}
+}
 
-	"kib": KiB, "KiB": KiB,
-	"mib": MiB, "MiB": MiB,
-	"gib": GiB, "GiB": GiB,
-	"tib": TiB, "TiB": TiB,
-	"pib": PiB, "PiB": PiB,
+// formatString returns the format directive, like "%-8.2q", with
+// the flags, width and precision of f and the given verb. It is
+// used to format a string representation with any other verb.
+func formatString(f fmt.State, verb rune) string {
+	b := []byte{'%'}
+	for _, c := range "+-# 0" {
+		if f.Flag(int(c)) {
+			b = append(b, byte(c))
+		}
+	}
+	if width, ok := f.Width(); ok {
+		b = strconv.AppendInt(b, int64(width), 10)
+	}
+	if prec, ok := f.Precision(); ok {
+		b = append(b, '.')
+		b = strconv.AppendInt(b, int64(prec), 10)
+	}
+	return string(b) + string(verb)
 }
 
-var bitsizeUnits = map[string]BitSize{
-	"bit": Bit, "Bit": Bit,
-	"kbit": KBit, "Kbit": KBit,
-	"mbit": MBit, "Mbit": MBit,
-	"gbit": GBit, "Gbit": GBit,
-	"tbit": TBit, "Tbit": TBit,
+// appendExactFraction appends the fraction r / base, with r < base,
+// as ".xyz" to dst. It uses the fewest digits such that parsing the
+// fraction, using the exact integer arithmetic of fraction, returns
+// r again.
+func appendExactFraction(dst []byte, r, base uint64) []byte {
+	var n int
+	var d, pow uint64 = 0, 1
+	for n < 19 {
+		n, pow = n+1, pow*10
+
+		// d = ceil(r * 10^n / base) such that rounding
+		// d * base / 10^n does not return less than r.
+		hi, lo := bits.Mul64(r, pow)
+		q, rem := bits.Div64(hi, lo, base)
+		if rem != 0 {
+			q++
+		}
+		if q >= pow {
+			continue
+		}
+		if fraction(q, pow, base) == r {
+			d = q
+			break
+		}
+	}
+
+	dst = append(dst, '.')
+	digits := dst[len(dst):]
+	for i := 0; i < n; i++ {
+		digits = append(digits, '0')
+	}
+	for i := n - 1; i >= 0; i-- {
+		digits[i] = '0' + byte(d%10)
+		d /= 10
+	}
+	for len(digits) > 1 && digits[len(digits)-1] == '0' {
+		digits = digits[:len(digits)-1]
+	}
+	return append(dst, digits...)
+}
+
+// compactNum removes trailing zeros, and the decimal point if no
+// digits remain, from the fraction of the formatted number b. The
+// number may be followed by a unit, like in "1.500KB".
+func compactNum(b []byte) []byte {
+	dot, end := -1, len(b)
+	for i, c := range b {
+		if c == '.' {
+			dot = i
+			continue
+		}
+		if (c < '0' || c > '9') && !(i == 0 && c == '-') {
+			end = i
+			break
+		}
+	}
+	if dot < 0 {
+		return b
+	}
+
+	n := end
+	for n > dot+1 && b[n-1] == '0' {
+		n--
+	}
+	if n == dot+1 {
+		n = dot
+	}
+	return append(b[:n], b[end:]...)
+}
+
+// log10 returns k if v is equal to 10^k and -1 otherwise.
+func log10(v int64) int {
+	if v <= 0 {
+		return -1
+	}
+	var k int
+	for ; v%10 == 0; v /= 10 {
+		k++
+	}
+	if v != 1 {
+		return -1
+	}
+	return k
+}
+
+// sizeUnitString returns the string representation of the Size
+// unit u for the format fmt, or the empty string if u is not a
+// unit of fmt.
+func sizeUnitString(u Size, fmt byte) string {
+	switch fmt {
+	case 'd', 'D':
+		var s string
+		switch u {
+		case Byte:
+			s = "B"
+		case KB:
+			s = "KB"
+		case MB:
+			s = "MB"
+		case GB:
+			s = "GB"
+		case TB:
+			s = "TB"
+		case PB:
+			s = "PB"
+		case EB:
+			s = "EB"
+		}
+		if fmt == 'd' {
+			return strings.ToLower(s)
+		}
+		return s
+	case 'b', 'B':
+		var s string
+		switch u {
+		case Byte:
+			s = "B"
+		case KiB:
+			s = "KiB"
+		case MiB:
+			s = "MiB"
+		case GiB:
+			s = "GiB"
+		case TiB:
+			s = "TiB"
+		case PiB:
+			s = "PiB"
+		case EiB:
+			s = "EiB"
+		}
+		if fmt == 'b' {
+			return strings.ToLower(s)
+		}
+		return s
+	default:
+		return ""
+	}
+}
+
+// sizeUnit returns the Size unit corresponding to s, if any.
+//
+// A switch on constant strings avoids hashing s on every call,
+// and is therefore faster than a map lookup.
+func sizeUnit[T string | []byte](s T) (Size, bool) {
+	switch string(s) {
+	case "b", "B":
+		return Byte, true
+	case "k", "K", "kb", "KB":
+		return KB, true
+	case "m", "M", "mb", "MB":
+		return MB, true
+	case "g", "G", "gb", "GB":
+		return GB, true
+	case "t", "T", "tb", "TB":
+		return TB, true
+	case "p", "P", "pb", "PB":
+		return PB, true
+	case "ki", "Ki", "kib", "KiB":
+		return KiB, true
+	case "mi", "Mi", "mib", "MiB":
+		return MiB, true
+	case "gi", "Gi", "gib", "GiB":
+		return GiB, true
+	case "ti", "Ti", "tib", "TiB":
+		return TiB, true
+	case "pi", "Pi", "pib", "PiB":
+		return PiB, true
+	case "eb", "EB":
+		return EB, true
+	case "eib", "EiB":
+		return EiB, true
+	default:
+		return 0, false
+	}
+}
+
+// bitsizeUnit returns the BitSize unit corresponding to s, if any.
+func bitsizeUnit(s string) (BitSize, bool) {
+	switch s {
+	case "bit", "Bit":
+		return Bit, true
+	case "kbit", "Kbit":
+		return KBit, true
+	case "mbit", "Mbit":
+		return MBit, true
+	case "gbit", "Gbit":
+		return GBit, true
+	case "tbit", "Tbit":
+		return TBit, true
+	case "pbit", "Pbit":
+		return PBit, true
+	case "ebit", "Ebit":
+		return EBit, true
+	case "nibble", "Nibble":
+		return Nibble, true
+	default:
+		return 0, false
+	}
 }
@@ -5,9 +5,13 @@
 package mem
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 var formatSizeTests = []struct {
@@ -22,7 +26,15 @@ var formatSizeTests = []struct {
 	{Size: 1*MB + 111*KB, Prec: 2, D: "1.11mb", B: "1.06mib"},                                   // 5
 	{Size: -1*MB - 111*KB, Prec: -1, D: "-1.111mb", B: "-1.05953216552734375mib"},               // 6
 	{Size: 1*GiB + 512*MiB, Prec: -1, D: "1.610612736gb", B: "1.5gib"},                          // 7
-	{Size: math.MaxInt64, Prec: -1, D: "9223.372036854775807pb", B: "8191.9999999999999991pib"}, // 8
+	{Size: math.MaxInt64, Prec: -1, D: "9.223372036854775807eb", B: "7.9999999999999999992eib"}, // 8
+	{Size: 5 * GB, Prec: -1, D: "5gb", B: "4.6566128730773926gib"},                              // 9
+	{Size: 5 * GiB, Prec: 2, D: "5.37gb", B: "5.00gib"},                                         // 10
+	{Size: 1*KB + 5*Byte, Prec: 4, D: "1.0050kb", B: "1005.0000b"},                              // 11
+	{Size: -1*TB - 20*GB, Prec: -1, D: "-1.02tb", B: "-949.9490261077880859gib"},                // 12
+	{Size: 1999, Prec: 2, D: "2.00kb", B: "1.95kib"},                                            // 13
+	{Size: -1999, Prec: 2, D: "-2.00kb", B: "-1.95kib"},                                         // 14
+	{Size: 2047, Prec: 2, D: "2.05kb", B: "2.00kib"},                                            // 15
+	{Size: -2047, Prec: 2, D: "-2.05kb", B: "-2.00kib"},                                         // 16
 }
 
 func TestFormatSize(t *testing.T) {
@@ -36,6 +48,37 @@ func TestFormatSize(t *testing.T) {
 	}
 }
 
+func TestAppendSize(t *testing.T) {
+	buf := make([]byte, 0, 64)
+	for i, test := range formatSizeTests {
+		for _, fmt := range []byte{'d', 'b'} {
+			want := FormatSize(test.Size, fmt, test.Prec)
+			if s := AppendSize([]byte("size="), test.Size, fmt, test.Prec); string(s) != "size="+want {
+				t.Fatalf("Test %d: got %s - want size=%s", i, s, want)
+			}
+
+			allocs := testing.AllocsPerRun(10, func() { buf = AppendSize(buf[:0], test.Size, fmt, test.Prec) })
+			if allocs != 0 {
+				t.Fatalf("Test %d: AppendSize allocates %v times", i, allocs)
+			}
+		}
+	}
+}
+
+func TestAppendBitSize(t *testing.T) {
+	buf := make([]byte, 0, 64)
+	for i, test := range bitsizeStringTests {
+		if s := AppendBitSize([]byte("size="), test.Size, 'D', -1); string(s) != "size="+test.String {
+			t.Fatalf("Test %d: got %s - want size=%s", i, s, test.String)
+		}
+
+		allocs := testing.AllocsPerRun(10, func() { buf = AppendBitSize(buf[:0], test.Size, 'D', -1) })
+		if allocs != 0 {
+			t.Fatalf("Test %d: AppendBitSize allocates %v times", i, allocs)
+		}
+	}
+}
+
 var formatParseSizeTests = []Size{
 	0, Byte, 512 * Byte, -Byte, -512 * Byte,
 	KB, KiB, 384 * KB, 384 * KiB, -KB, -KiB, -732 * KB, -732 * KiB,
@@ -43,6 +86,40 @@ var formatParseSizeTests = []Size{
 	GB, GiB, 740 * GB, 59 * GiB, -GB, -GiB, -64*GB - 837*MB - 848*Byte,
 	TB, TiB, 182 * TB, 485 * TiB, -TB, -TiB, 301*TB + 643*MB - 553*Byte,
 	PB, PiB, 871 * PB, 131 * PiB, -PB, -PiB, math.MaxInt64, math.MinInt64,
+	EB, EiB, -EB, -EiB, 3 * EiB / 2, math.MaxInt64 - 1000, math.MinInt64 + 1,
+	KiB + 1, MiB + 1, GiB + 1, TiB + 1, PiB + 1, EiB + 1, 1<<62 + 1, -(PiB + 1),
+	7259471, 13902808415, -2460234008974, -173519809664762, -1064324325214528,
+	1<<53 - 1, 1<<53 + 1, 3<<52 - 1, -(5<<50 + 3), math.MaxInt64 - PiB,
+}
+
+var parseSizeRoundingTests = []struct {
+	String string
+	Size   Size
+}{
+	{String: "1.0000000000000008881784197001252PiB", Size: PiB + 1}, // 0
+	{String: "1.000000000000000888PiB", Size: PiB + 1},              // 1
+	{String: "6.9231710433959961MiB", Size: 7259471},                // 2
+	{String: "-2.23756979628342378TiB", Size: -2460234008974},       // 3
+	{String: "12.9479993274435401GiB", Size: 13902808415},           // 4
+	{String: "0.999999999999999999999999PiB", Size: PiB},            // 5
+	{String: "7.999999999999999999EiB", Size: math.MaxInt64},        // 6
+	{String: "0.4B", Size: 0},                                       // 7
+	{String: "0.5B", Size: 1},                                       // 8
+	{String: "-0.5B", Size: -1},                                     // 9
+	{String: "1.9999KB", Size: 2000},                                // 10
+	{String: "1.99949KB", Size: 1999},                               // 11
+}
+
+func TestParseSize_Rounding(t *testing.T) {
+	for i, test := range parseSizeRoundingTests {
+		size, err := ParseSize(test.String)
+		if err != nil {
+			t.Fatalf("Test %d: failed to parse Size: %v", i, err)
+		}
+		if size != test.Size {
+			t.Fatalf("Test %d: got '%d' - want '%d'", i, size, test.Size)
+		}
+	}
 }
 
 func TestFormatParseSize(t *testing.T) {
@@ -51,15 +128,18 @@ func TestFormatParseSize(t *testing.T) {
 	for _, f := range fmts {
 		for _, prec := range precs {
 			for _, s := range formatParseSizeTests {
+				if prec >= 0 && s.Abs() >= EB {
+					continue // 16 digits are not sufficient for EB and EiB
+				}
 				v := FormatSize(s, f, prec)
 				w, err := ParseSize(v)
 				if err != nil {
-					details := fmt.Sprintf("formatted '%d' with fmt='%c' and prec='%d'", s, f, prec)
+					details := fmt.Sprintf("formatted '%d' with fmt='%c' and prec='%d'", int64(s), f, prec)
 					t.Fatalf("Failed to parse size string '%s' - %s", v, details)
 				}
 				if w != s {
-					details := fmt.Sprintf("formatted '%d' with fmt='%c' and prec='%d'", s, f, prec)
-					t.Fatalf("Parsed size does not match original size: got '%v' ('%d') - want '%v' ('%d') - %s", w, w, s, s, details)
+					details := fmt.Sprintf("formatted '%d' with fmt='%c' and prec='%d'", int64(s), f, prec)
+					t.Fatalf("Parsed size does not match original size: got '%v' ('%d') - want '%v' ('%d') - %s", w, int64(w), s, int64(s), details)
 				}
 			}
 		}
@@ -81,17 +161,73 @@ var parseSizeTests = []struct {
 	{String: "1.05953216552734375MiB", Size: 1*MB + 111*KB},    // 7
 	{String: "1.610612736gb", Size: 1*GiB + 512*MiB},           // 8
 	{String: "1.5gib", Size: 1*GiB + 512*MiB},                  // 9
-	{String: "8191.99999999999999991PiB", Size: math.MaxInt64}, // 11
+	{String: "8191.99999999999999991PiB", Size: math.MaxInt64}, // 10
+	{String: "9.223372036854775807EB", Size: math.MaxInt64},    // 11
+	{String: "7.9999999999999999992eib", Size: math.MaxInt64},  // 12
+	{String: "-8EiB", Size: math.MinInt64},                     // 13
+	{String: "1.5eb", Size: 1500 * PB},                         // 14
+	{String: "512K", Size: 512 * KB},                           // 15
+	{String: "1.5m", Size: 1500 * KB},                          // 16
+	{String: "-2G", Size: -2 * GB},                             // 17
+	{String: "4T", Size: 4 * TB},                               // 18
+	{String: "1p", Size: PB},                                   // 19
+	{String: "1,073,741,824B", Size: GiB},                      // 20
+	{String: "-1,536KiB", Size: -1536 * KiB},                   // 21
+	{String: "1,000.5KB", Size: 1000*KB + 500},                 // 22
+	{String: "999,999B", Size: 999999},                         // 23
 
-	{String: "0", ShouldFail: true},          // 12
-	{String: "--0b", ShouldFail: true},       // 13
-	{String: "+-0b", ShouldFail: true},       // 14
-	{String: " 0B", ShouldFail: true},        // 15
-	{String: "0B ", ShouldFail: true},        // 16
-	{String: "1.125.0KB ", ShouldFail: true}, // 17
-	{String: "1.25.0KB ", ShouldFail: true},  // 18
-	{String: "8bit ", ShouldFail: true},      // 19
-	{String: "8Kbit ", ShouldFail: true},     // 20
+	{String: "0", ShouldFail: true},                                             // 24
+	{String: "--0b", ShouldFail: true},                                          // 25
+	{String: "+-0b", ShouldFail: true},                                          // 26
+	{String: " 0B", ShouldFail: true},                                           // 27
+	{String: "0B ", ShouldFail: true},                                           // 28
+	{String: "1.125.0KB ", ShouldFail: true},                                    // 29
+	{String: "1.25.0KB ", ShouldFail: true},                                     // 30
+	{String: "8bit ", ShouldFail: true},                                         // 31
+	{String: "8Kbit ", ShouldFail: true},                                        // 32
+	{String: "8Ki", Size: 8 * KiB},                                              // 33
+	{String: "8KIB", ShouldFail: true},                                          // 34
+	{String: ",000B", ShouldFail: true},                                         // 35
+	{String: "1,00B", ShouldFail: true},                                         // 36
+	{String: "1,0000B", ShouldFail: true},                                       // 37
+	{String: "1000,000B", ShouldFail: true},                                     // 38
+	{String: "1,,000B", ShouldFail: true},                                       // 39
+	{String: "1,000,B", ShouldFail: true},                                       // 40
+	{String: "1,000,00B", ShouldFail: true},                                     // 41
+	{String: "1,.5KB", ShouldFail: true},                                        // 42
+	{String: "1.000,5KB", ShouldFail: true},                                     // 43
+	{String: "1,000", ShouldFail: true},                                         // 44
+	{String: "+,100B", ShouldFail: true},                                        // 45
+	{String: "5 MB", Size: 5 * MB},                                              // 46
+	{String: "512\tKiB", Size: 512 * KiB},                                       // 47
+	{String: "-1.5 KiB", Size: -1536},                                           // 48
+	{String: "1,000 B", Size: 1000},                                             // 49
+	{String: "5  MB", ShouldFail: true},                                         // 50
+	{String: "5 \tMB", ShouldFail: true},                                        // 51
+	{String: "5 ", ShouldFail: true},                                            // 52
+	{String: "- 5MB", ShouldFail: true},                                         // 53
+	{String: " 5 MB", ShouldFail: true},                                         // 54
+	{String: "5 MB ", ShouldFail: true},                                         // 55
+	{String: "1.5e3MB", Size: 1500 * MB},                                        // 56
+	{String: "1.5E3MB", Size: 1500 * MB},                                        // 57
+	{String: "-2e-3GB", Size: -2 * MB},                                          // 58
+	{String: "25e+1KiB", Size: 250 * KiB},                                       // 59
+	{String: "0.0625e2KiB", Size: 6400},                                         // 60
+	{String: "0e999EB", Size: 0},                                                // 61
+	{String: "1e-999EiB", Size: 0},                                              // 62
+	{String: "1.5e3 MB", Size: 1500 * MB},                                       // 63
+	{String: "9.223372036854775807e18B", Size: math.MaxInt64},                   // 64
+	{String: "1eb", Size: EB},                                                   // 65
+	{String: "1e3", ShouldFail: true},                                           // 66
+	{String: "1e999B", ShouldFail: true},                                        // 67
+	{String: "1,000e3B", ShouldFail: true},                                      // 68
+	{String: ".e3B", ShouldFail: true},                                          // 69
+	{String: "1.5e3.5MB", ShouldFail: true},                                     // 70
+	{String: "1e-MB", ShouldFail: true},                                         // 71
+	{String: "1234567890123456789012345678901234567890123e-40KB", Size: 123457}, // 72
+	{String: "0.00000000000000000000000000001e30KB", Size: 10 * KB},             // 73
+	{String: "8.0EiB", ShouldFail: true},                                        // 74
+	{String: "8.EiB", ShouldFail: true},                                         // 75
 }
 
 func TestParseSize(t *testing.T) {
@@ -111,3 +247,1171 @@ func TestParseSize(t *testing.T) {
 		}
 	}
 }
+
+var formatBandwidthTests = []struct {
+	Bandwidth Bandwidth
+	Prec      int
+	D         string
+}{
+	{Bandwidth: 0, Prec: -1, D: "0b/s"},                                          // 0
+	{Bandwidth: BytePerSecond, Prec: -1, D: "1b/s"},                              // 1
+	{Bandwidth: -4 * BitPerSecond, Prec: -1, D: "-0.5b/s"},                       // 2
+	{Bandwidth: 1*MBytePerSecond + 111*KBytePerSecond, Prec: -1, D: "1.111mb/s"}, // 3
+	{Bandwidth: 1*MBytePerSecond + 111*KBytePerSecond, Prec: 2, D: "1.11mb/s"},   // 4
+	{Bandwidth: 100 * MBitPerSecond, Prec: -1, D: "12.5mb/s"},                    // 5
+	{Bandwidth: 3 * PBytePerSecond, Prec: 1, D: "3.0pb/s"},                       // 6
+}
+
+func TestFormatBandwidth(t *testing.T) {
+	for i, test := range formatBandwidthTests {
+		if d := FormatBandwidth(test.Bandwidth, 'd', test.Prec); d != test.D {
+			t.Fatalf("Test %d: format 'd': got %s - want %s", i, d, test.D)
+		}
+	}
+	if s := FormatBandwidth(MBytePerSecond, 'x', -1); s != "%x" {
+		t.Fatalf("Invalid format: got %s - want %%x", s)
+	}
+}
+
+func TestFormatBandwidth_Formats(t *testing.T) {
+	for i, test := range formatBandwidthFormatTests {
+		s := FormatBandwidth(test.Bandwidth, test.Fmt, test.Prec)
+		if s != test.String {
+			t.Fatalf("Test %d: format '%c': got %s - want %s", i, test.Fmt, s, test.String)
+		}
+		if test.Prec >= 0 {
+			continue
+		}
+		b, err := ParseBandwidth(s)
+		if err != nil {
+			t.Fatalf("Test %d: failed to parse bandwidth: %v", i, err)
+		}
+		if b != test.Bandwidth {
+			t.Fatalf("Test %d: got %d - want %d", i, b, test.Bandwidth)
+		}
+	}
+}
+
+var formatBandwidthFormatTests = []struct {
+	Bandwidth Bandwidth
+	Fmt       byte
+	Prec      int
+	String    string
+}{
+	{Bandwidth: 0, Fmt: 'b', Prec: -1, String: "0b/s"},                           // 0
+	{Bandwidth: 0, Fmt: 'B', Prec: -1, String: "0B/s"},                           // 1
+	{Bandwidth: 0, Fmt: 't', Prec: -1, String: "0bit/s"},                         // 2
+	{Bandwidth: 0, Fmt: 'T', Prec: -1, String: "0bit/s"},                         // 3
+	{Bandwidth: MiBytePerSecond, Fmt: 'b', Prec: -1, String: "1mib/s"},           // 4
+	{Bandwidth: 3 * MiBytePerSecond / 2, Fmt: 'B', Prec: -1, String: "1.5MiB/s"}, // 5
+	{Bandwidth: -KiBytePerSecond, Fmt: 'B', Prec: -1, String: "-1KiB/s"},         // 6
+	{Bandwidth: MBytePerSecond, Fmt: 'B', Prec: 2, String: "976.56KiB/s"},        // 7
+	{Bandwidth: 2 * PiBytePerSecond, Fmt: 'B', Prec: -1, String: "2PiB/s"},       // 8
+	{Bandwidth: BitPerSecond, Fmt: 'B', Prec: -1, String: "0.125B/s"},            // 9
+	{Bandwidth: 100 * MBitPerSecond, Fmt: 't', Prec: -1, String: "100mbit/s"},    // 10
+	{Bandwidth: 100 * MBitPerSecond, Fmt: 'T', Prec: -1, String: "100Mbit/s"},    // 11
+	{Bandwidth: -1500 * KBitPerSecond, Fmt: 'T', Prec: -1, String: "-1.5Mbit/s"}, // 12
+	{Bandwidth: 7 * BitPerSecond, Fmt: 'T', Prec: -1, String: "7bit/s"},          // 13
+	{Bandwidth: 10 * TBitPerSecond, Fmt: 'T', Prec: 1, String: "10.0Tbit/s"},     // 14
+	{Bandwidth: GBytePerSecond, Fmt: 'T', Prec: -1, String: "8Gbit/s"},           // 15
+	{Bandwidth: PBitPerSecond, Fmt: 'T', Prec: -1, String: "1Pbit/s"},            // 16
+	{Bandwidth: -3 * EBitPerSecond, Fmt: 't', Prec: -1, String: "-3ebit/s"},      // 17
+	{Bandwidth: math.MaxInt64, Fmt: 'T', Prec: 2, String: "9.22Ebit/s"},          // 18
+	{Bandwidth: EBytePerSecond, Fmt: 'D', Prec: -1, String: "1EB/s"},             // 19
+	{Bandwidth: math.MaxInt64, Fmt: 'B', Prec: 2, String: "1024.00PiB/s"},        // 20
+}
+
+var formatRateTests = []struct {
+	Size     Size
+	Duration time.Duration
+	Prec     int
+	Rate     string
+}{
+	{Size: 500 * MB, Duration: 4 * time.Second, Prec: -1, Rate: "125MB/s"},       // 0
+	{Size: 1 * GB, Duration: 3 * time.Second, Prec: 2, Rate: "333.33MB/s"},       // 1
+	{Size: 1 * GB, Duration: 0, Prec: -1, Rate: "0B/s"},                          // 2
+	{Size: 64 * KB, Duration: 500 * time.Millisecond, Prec: -1, Rate: "128KB/s"}, // 3
+}
+
+func TestFormatRate(t *testing.T) {
+	for i, test := range formatRateTests {
+		if rate := FormatRate(test.Size, test.Duration, 'D', test.Prec); rate != test.Rate {
+			t.Fatalf("Test %d: got %s - want %s", i, rate, test.Rate)
+		}
+	}
+}
+
+var parseSizesTests = []struct {
+	String     string
+	Sizes      []Size
+	ShouldFail bool
+}{
+	{String: "1MB", Sizes: []Size{MB}},                                   // 0
+	{String: "1MB,2MB,4MB", Sizes: []Size{MB, 2 * MB, 4 * MB}},           // 1
+	{String: " 1MB , 2MiB,\t-4KB ", Sizes: []Size{MB, 2 * MiB, -4 * KB}}, // 2
+	{String: "", ShouldFail: true},                                       // 3
+	{String: "1MB,", ShouldFail: true},                                   // 4
+	{String: "1MB,,2MB", ShouldFail: true},                               // 5
+	{String: "1MB,2XB", ShouldFail: true},                                // 6
+}
+
+func TestParseSizes(t *testing.T) {
+	for i, test := range parseSizesTests {
+		sizes, err := ParseSizes(test.String)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to parse sizes: %v", i, err)
+		}
+		if len(sizes) != len(test.Sizes) {
+			t.Fatalf("Test %d: got %d sizes - want %d", i, len(sizes), len(test.Sizes))
+		}
+		for j := range sizes {
+			if sizes[j] != test.Sizes[j] {
+				t.Fatalf("Test %d: element %d: got %v - want %v", i, j, sizes[j], test.Sizes[j])
+			}
+		}
+	}
+
+	const Invalid = "1MB,2XB"
+	if _, err := ParseSizes(Invalid); err == nil || !strings.HasSuffix(err.Error(), "at list index 1") {
+		t.Fatalf("Error does not report invalid element: %v", err)
+	}
+}
+
+func TestParseSizeStrict(t *testing.T) {
+	for i, test := range parseSizeStrictTests {
+		size, err := ParseSizeStrict(test.String)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to parse Size: %v", i, err)
+		}
+		if test.Syntax && !errors.Is(err, ErrSyntax) {
+			t.Fatalf("Test %d: got error '%v' - want %v", i, err, ErrSyntax)
+		}
+		if err != nil {
+			continue
+		}
+		if size != test.Size {
+			t.Fatalf("Test %d: got '%d (%s)' - want %d (%s)", i, size, size, test.Size, test.Size)
+		}
+	}
+}
+
+var parseSizeStrictTests = []struct {
+	String     string
+	Size       Size
+	ShouldFail bool
+	Syntax     bool
+}{
+	{String: "0B", Size: 0},                           // 0
+	{String: "7KB", Size: 7 * KB},                     // 1
+	{String: "-0.5KB", Size: -500},                    // 2
+	{String: "+10.05MB", Size: 10*MB + 50*KB},         // 3
+	{String: "1,024KiB", Size: MiB},                   // 4
+	{String: "0.007KB", Size: 7},                      // 5
+	{String: "007KB", ShouldFail: true, Syntax: true}, // 6
+	{String: "-00KB", ShouldFail: true, Syntax: true}, // 7
+	{String: "+", ShouldFail: true, Syntax: true},     // 8
+	{String: "-", ShouldFail: true, Syntax: true},     // 9
+	{String: "-KB", ShouldFail: true, Syntax: true},   // 10
+	{String: ".5KB", ShouldFail: true, Syntax: true},  // 11
+	{String: "-.5KB", ShouldFail: true, Syntax: true}, // 12
+	{String: "5.KB", ShouldFail: true, Syntax: true},  // 13
+	{String: "", ShouldFail: true, Syntax: true},      // 14
+	{String: "5", ShouldFail: true},                   // 15
+	{String: "5XB", ShouldFail: true},                 // 16
+}
+
+func TestParseSizeFraction(t *testing.T) {
+	for i, test := range parseSizeFractionTests {
+		size, err := ParseSizeFraction(test.String)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to parse Size: %v", i, err)
+		}
+		if err != nil {
+			continue
+		}
+		if size != test.Size {
+			t.Fatalf("Test %d: got '%d (%s)' - want %d (%s)", i, size, size, test.Size, test.Size)
+		}
+	}
+}
+
+var parseSizeFractionTests = []struct {
+	String     string
+	Size       Size
+	ShouldFail bool
+}{
+	{String: "1/2GB", Size: 500 * MB},                         // 0
+	{String: "1/2GiB", Size: 512 * MiB},                       // 1
+	{String: "-3/4KiB", Size: -768},                           // 2
+	{String: "+3/4KiB", Size: 768},                            // 3
+	{String: "1/3KB", Size: 333},                              // 4
+	{String: "2/3KB", Size: 667},                              // 5
+	{String: "-2/3KB", Size: -667},                            // 6
+	{String: "1/2B", Size: 1},                                 // 7
+	{String: "0/5MB", Size: 0},                                // 8
+	{String: "1.5GB", Size: 1500 * MB},                        // 9
+	{String: "1/36893488147419103232B", ShouldFail: true},     // 10
+	{String: "8191/1PiB", Size: 8191 * PiB},                   // 11
+	{String: "-8192/1PiB", Size: math.MinInt64},               // 12
+	{String: "8192/1PiB", ShouldFail: true},                   // 13
+	{String: "36893488147419103232/1B", ShouldFail: true},     // 14
+	{String: "1/0GB", ShouldFail: true},                       // 15
+	{String: "1/-2GB", ShouldFail: true},                      // 16
+	{String: "1.5/2GB", ShouldFail: true},                     // 17
+	{String: "/2GB", ShouldFail: true},                        // 18
+	{String: "1/GB", ShouldFail: true},                        // 19
+	{String: "1/2", ShouldFail: true},                         // 20
+	{String: "1/2/3GB", ShouldFail: true},                     // 21
+	{String: "1/2XB", ShouldFail: true},                       // 22
+	{String: "--1/2GB", ShouldFail: true},                     // 23
+	{String: "18428297329635842064/999KB", ShouldFail: true},  // 24
+	{String: "-18428297329635842064/999KB", ShouldFail: true}, // 25
+}
+
+func TestParseSizeQuery(t *testing.T) {
+	for i, test := range parseSizeQueryTests {
+		size, err := ParseSizeQuery(test.String)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to parse Size: %v", i, err)
+		}
+		if err != nil {
+			continue
+		}
+		if size != test.Size {
+			t.Fatalf("Test %d: got '%d (%s)' - want %d (%s)", i, size, size, test.Size, test.Size)
+		}
+		if s, err := ParseSizeQuery(size.Query()); err != nil || s != size {
+			t.Fatalf("Test %d: failed to parse query %s: got %d - want %d", i, size.Query(), s, size)
+		}
+	}
+}
+
+var parseSizeQueryTests = []struct {
+	String     string
+	Size       Size
+	ShouldFail bool
+}{
+	{String: "0", Size: 0},                                // 0
+	{String: "1610612736", Size: 1*GiB + 512*MiB},         // 1
+	{String: "-1024", Size: -KiB},                         // 2
+	{String: "1.5GiB", Size: 1*GiB + 512*MiB},             // 3
+	{String: "5MB", Size: 5 * MB},                         // 4
+	{String: "9223372036854775807", Size: math.MaxInt64},  // 5
+	{String: "-9223372036854775808", Size: math.MinInt64}, // 6
+	{String: "9223372036854775808", ShouldFail: true},     // 7
+	{String: "", ShouldFail: true},                        // 8
+	{String: "1.5", ShouldFail: true},                     // 9
+	{String: "5 MB", Size: 5 * MB},                        // 10
+	{String: "5%20MB", ShouldFail: true},                  // 11
+}
+
+var formatSizeDiffTests = []struct {
+	Before, After Size
+	Diff          string
+}{
+	{Before: 1200 * MB, After: 980 * MB, Diff: "before: 1.2GB, after: 980MB (-220MB, -18.3%)"}, // 0
+	{Before: MB, After: 2 * MB, Diff: "before: 1MB, after: 2MB (+1MB, +100.0%)"},               // 1
+	{Before: MB, After: MB, Diff: "before: 1MB, after: 1MB (0B, 0.0%)"},                        // 2
+	{Before: 0, After: MB, Diff: "before: 0B, after: 1MB (+1MB)"},                              // 3
+	{Before: 0, After: 0, Diff: "before: 0B, after: 0B (0B)"},                                  // 4
+	{Before: MB, After: 0, Diff: "before: 1MB, after: 0B (-1MB, -100.0%)"},                     // 5
+}
+
+func TestFormatSizeDiff(t *testing.T) {
+	for i, test := range formatSizeDiffTests {
+		if diff := FormatSizeDiff(test.Before, test.After); diff != test.Diff {
+			t.Fatalf("Test %d: got '%s' - want '%s'", i, diff, test.Diff)
+		}
+	}
+}
+
+var formatBitSizeNibbleTests = []struct {
+	Size BitSize
+	Prec int
+	N    string
+}{
+	{Size: 0, Prec: -1, N: "0Nibble"},            // 0
+	{Size: Nibble, Prec: -1, N: "1Nibble"},       // 1
+	{Size: 2 * Bit, Prec: -1, N: "0.5Nibble"},    // 2
+	{Size: -3 * Bit, Prec: -1, N: "-0.75Nibble"}, // 3
+	{Size: KBit, Prec: -1, N: "250Nibble"},       // 4
+	{Size: 7 * Bit, Prec: 1, N: "1.8Nibble"},     // 5
+}
+
+func TestFormatBitSize_Nibble(t *testing.T) {
+	for i, test := range formatBitSizeNibbleTests {
+		if n := FormatBitSize(test.Size, 'N', test.Prec); n != test.N {
+			t.Fatalf("Test %d: format 'N': got %s - want %s", i, n, test.N)
+		}
+		v, err := ParseBitSize(FormatBitSize(test.Size, 'n', -1))
+		if err != nil {
+			t.Fatalf("Test %d: failed to parse bit size: %v", i, err)
+		}
+		if v != test.Size {
+			t.Fatalf("Test %d: got %d - want %d", i, v, test.Size)
+		}
+	}
+}
+
+var formatProgressTests = []struct {
+	Done, Total Size
+	Progress    string
+}{
+	{Done: 1500 * MB, Total: 4 * GB, Progress: "1.5GB / 4GB (37.5%)"}, // 0
+	{Done: 0, Total: 4 * GB, Progress: "0B / 4GB (0.0%)"},             // 1
+	{Done: 4 * GB, Total: 4 * GB, Progress: "4GB / 4GB (100.0%)"},     // 2
+	{Done: 5 * GB, Total: 4 * GB, Progress: "5GB / 4GB (100.0%)"},     // 3
+	{Done: -MB, Total: 4 * GB, Progress: "-1MB / 4GB (0.0%)"},         // 4
+	{Done: 0, Total: 0, Progress: "0B / 0B (100.0%)"},                 // 5
+	{Done: MB, Total: 3 * MB, Progress: "1MB / 3MB (33.3%)"},          // 6
+}
+
+func TestFormatProgress(t *testing.T) {
+	for i, test := range formatProgressTests {
+		if p := FormatProgress(test.Done, test.Total); p != test.Progress {
+			t.Fatalf("Test %d: got '%s' - want '%s'", i, p, test.Progress)
+		}
+	}
+}
+
+var parseSizeFlexibleTests = []struct {
+	String     string
+	Size       Size
+	ShouldFail bool
+}{
+	{String: "5MB", Size: 5 * MB},        // 0
+	{String: " 5MB", Size: 5 * MB},       // 1
+	{String: "5MB\n", Size: 5 * MB},      // 2
+	{String: "\t-1.5KiB ", Size: -1536},  // 3
+	{String: "B1024", Size: 1024 * Byte}, // 4
+	{String: "MB5", Size: 5 * MB},        // 5
+	{String: "KiB -1.5", Size: -1536},    // 6
+	{String: "  gb+2 ", Size: 2 * GB},    // 7
+	{String: "", ShouldFail: true},       // 8
+	{String: "   ", ShouldFail: true},    // 9
+	{String: "MB", ShouldFail: true},     // 10
+	{String: "5", ShouldFail: true},      // 11
+	{String: "XB5", ShouldFail: true},    // 12
+	{String: "MB5MB", ShouldFail: true},  // 13
+	{String: "$5MB", ShouldFail: true},   // 14
+	{String: "5 MB", Size: 5 * MB},       // 15
+}
+
+func TestParseSizeFlexible(t *testing.T) {
+	for i, test := range parseSizeFlexibleTests {
+		size, err := ParseSizeFlexible(test.String)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to parse Size: %v", i, err)
+		}
+		if err != nil {
+			continue
+		}
+		if size != test.Size {
+			t.Fatalf("Test %d: got '%d (%s)' - want %d (%s)", i, size, size, test.Size, test.Size)
+		}
+	}
+}
+
+func TestParseSizeBytes(t *testing.T) {
+	for i, test := range parseSizeTests {
+		b := []byte(test.String)
+		size, err := ParseSizeBytes(b)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to parse Size: %v", i, err)
+		}
+		if err != nil {
+			continue
+		}
+		if size != test.Size {
+			t.Fatalf("Test %d: got '%d (%s)' - want %d (%s)", i, size, size, test.Size, test.Size)
+		}
+
+		allocs := testing.AllocsPerRun(10, func() { ParseSizeBytes(b) })
+		if allocs != 0 {
+			t.Fatalf("Test %d: ParseSizeBytes allocates %v times", i, allocs)
+		}
+	}
+}
+
+func TestSizeUnit(t *testing.T) {
+	units := map[string]Size{
+		"b": Byte, "B": Byte,
+
+		"kb": KB, "KB": KB,
+		"mb": MB, "MB": MB,
+		"gb": GB, "GB": GB,
+		"tb": TB, "TB": TB,
+		"pb": PB, "PB": PB,
+		"eb": EB, "EB": EB,
+
+		"k": KB, "K": KB,
+		"m": MB, "M": MB,
+		"g": GB, "G": GB,
+		"t": TB, "T": TB,
+		"p": PB, "P": PB,
+
+		"ki": KiB, "Ki": KiB,
+		"mi": MiB, "Mi": MiB,
+		"gi": GiB, "Gi": GiB,
+		"ti": TiB, "Ti": TiB,
+		"pi": PiB, "Pi": PiB,
+
+		"kib": KiB, "KiB": KiB,
+		"mib": MiB, "MiB": MiB,
+		"gib": GiB, "GiB": GiB,
+		"tib": TiB, "TiB": TiB,
+		"pib": PiB, "PiB": PiB,
+		"eib": EiB, "EiB": EiB,
+	}
+	for name := range units {
+		for _, s := range casePermutations(name) {
+			want, ok := units[s]
+			unit, found := sizeUnit(s)
+			if found != ok {
+				t.Fatalf("Unit '%s': got %v - want %v", s, found, ok)
+			}
+			if unit != want {
+				t.Fatalf("Unit '%s': got %d - want %d", s, unit, want)
+			}
+		}
+	}
+}
+
+func TestBitSizeUnit(t *testing.T) {
+	units := map[string]BitSize{
+		"bit": Bit, "Bit": Bit,
+		"kbit": KBit, "Kbit": KBit,
+		"mbit": MBit, "Mbit": MBit,
+		"gbit": GBit, "Gbit": GBit,
+		"tbit": TBit, "Tbit": TBit,
+		"pbit": PBit, "Pbit": PBit,
+		"ebit": EBit, "Ebit": EBit,
+
+		"nibble": Nibble, "Nibble": Nibble,
+	}
+	for name := range units {
+		for _, s := range casePermutations(name) {
+			want, ok := units[s]
+			unit, found := bitsizeUnit(s)
+			if found != ok {
+				t.Fatalf("Unit '%s': got %v - want %v", s, found, ok)
+			}
+			if unit != want {
+				t.Fatalf("Unit '%s': got %d - want %d", s, unit, want)
+			}
+		}
+	}
+}
+
+// casePermutations returns all lower and upper case
+// permutations of the ASCII string s.
+func casePermutations(s string) []string {
+	if s == "" {
+		return []string{""}
+	}
+	var perms []string
+	for _, suffix := range casePermutations(s[1:]) {
+		lower, upper := strings.ToLower(s[:1]), strings.ToUpper(s[:1])
+		perms = append(perms, lower+suffix)
+		if upper != lower {
+			perms = append(perms, upper+suffix)
+		}
+	}
+	return perms
+}
+
+var formatBandwidthTCTests = []struct {
+	Bandwidth Bandwidth
+	TC        string
+}{
+	{Bandwidth: 0, TC: "0bit"},                                      // 0
+	{Bandwidth: 512 * BitPerSecond, TC: "512bit"},                   // 1
+	{Bandwidth: 100 * MBitPerSecond, TC: "100mbit"},                 // 2
+	{Bandwidth: GBitPerSecond, TC: "1gbit"},                         // 3
+	{Bandwidth: 1*GBitPerSecond + 500*MBitPerSecond, TC: "1.5gbit"}, // 4
+	{Bandwidth: 10 * MBytePerSecond, TC: "80mbit"},                  // 5
+	{Bandwidth: MiBytePerSecond, TC: "8.388608mbit"},                // 6
+	{Bandwidth: 40 * TBitPerSecond, TC: "40tbit"},                   // 7
+	{Bandwidth: 1*KBitPerSecond + 1, TC: "1.001kbit"},               // 8
+	{Bandwidth: 5 * PBitPerSecond, TC: "5pbit"},                     // 9
+	{Bandwidth: 2 * EBitPerSecond, TC: "2ebit"},                     // 10
+}
+
+func TestFormatBandwidthTC(t *testing.T) {
+	for i, test := range formatBandwidthTCTests {
+		if tc := FormatBandwidthTC(test.Bandwidth); tc != test.TC {
+			t.Fatalf("Test %d: got %s - want %s", i, tc, test.TC)
+		}
+	}
+}
+
+var formatSizeWithCompactTests = []struct {
+	Size   Size
+	Fmt    byte
+	Prec   int
+	String string
+}{
+	{Size: 0, Fmt: 'D', Prec: 4, String: "0B"},                   // 0
+	{Size: 2500 * MB, Fmt: 'D', Prec: 4, String: "2.5GB"},        // 1
+	{Size: 2 * GB, Fmt: 'D', Prec: 4, String: "2GB"},             // 2
+	{Size: 1*GiB + 512*MiB, Fmt: 'B', Prec: 4, String: "1.5GiB"}, // 3
+	{Size: 1999, Fmt: 'D', Prec: 2, String: "2KB"},               // 4
+	{Size: -1999, Fmt: 'd', Prec: 2, String: "-2kb"},             // 5
+	{Size: 1234567, Fmt: 'D', Prec: 2, String: "1.23MB"},         // 6
+	{Size: -500, Fmt: 'D', Prec: 3, String: "-500B"},             // 7
+	{Size: 1*MB + 111*KB, Fmt: 'b', Prec: 2, String: "1.06mib"},  // 8
+	{Size: 1*MB + 111*KB, Fmt: 'D', Prec: -1, String: "1.111MB"}, // 9
+	{Size: 1*MB + 100*KB, Fmt: 'D', Prec: 0, String: "1MB"},      // 10
+}
+
+func TestFormatSizeWith_Compact(t *testing.T) {
+	for i, test := range formatSizeWithCompactTests {
+		s := FormatSizeWith(test.Size, test.Fmt, test.Prec, FormatOptions{Compact: true})
+		if s != test.String {
+			t.Fatalf("Test %d: got %s - want %s", i, s, test.String)
+		}
+		if s, f := FormatSizeWith(test.Size, test.Fmt, test.Prec, FormatOptions{}), FormatSize(test.Size, test.Fmt, test.Prec); s != f {
+			t.Fatalf("Test %d: got %s - want %s", i, s, f)
+		}
+	}
+}
+
+func TestFormatSizeWith_MinUnit(t *testing.T) {
+	for i, test := range formatSizeWithMinUnitTests {
+		s := FormatSizeWith(test.Size, test.Fmt, test.Prec, FormatOptions{MinUnit: test.MinUnit})
+		if s != test.String {
+			t.Fatalf("Test %d: got %s - want %s", i, s, test.String)
+		}
+	}
+}
+
+var formatSizeWithMinUnitTests = []struct {
+	Size    Size
+	Fmt     byte
+	Prec    int
+	MinUnit Size
+	String  string
+}{
+	{Size: 500, Fmt: 'D', Prec: -1, MinUnit: KB, String: "0.5KB"},           // 0
+	{Size: -500, Fmt: 'D', Prec: -1, MinUnit: KB, String: "-0.5KB"},         // 1
+	{Size: 0, Fmt: 'D', Prec: -1, MinUnit: KB, String: "0KB"},               // 2
+	{Size: 0, Fmt: 'd', Prec: 2, MinUnit: MB, String: "0.00mb"},             // 3
+	{Size: 1500, Fmt: 'D', Prec: -1, MinUnit: KB, String: "1.5KB"},          // 4
+	{Size: 2 * GB, Fmt: 'D', Prec: -1, MinUnit: MB, String: "2GB"},          // 5
+	{Size: 260 * KB, Fmt: 'd', Prec: 1, MinUnit: MB, String: "0.3mb"},       // 6
+	{Size: 512, Fmt: 'B', Prec: -1, MinUnit: KiB, String: "0.5KiB"},         // 7
+	{Size: 512, Fmt: 'b', Prec: -1, MinUnit: KiB, String: "0.5kib"},         // 8
+	{Size: 500, Fmt: 'B', Prec: -1, MinUnit: KB, String: "500B"},            // 9
+	{Size: 500, Fmt: 'D', Prec: -1, MinUnit: KiB, String: "500B"},           // 10
+	{Size: 500, Fmt: 'D', Prec: -1, MinUnit: 0, String: "500B"},             // 11
+	{Size: 500, Fmt: 'D', Prec: -1, MinUnit: Byte, String: "500B"},          // 12
+	{Size: 500, Fmt: 'x', Prec: -1, MinUnit: KB, String: "%x"},              // 13
+	{Size: math.MaxInt64, Fmt: 'D', Prec: 2, MinUnit: PB, String: "9.22EB"}, // 14
+}
+
+func TestFormatSizeWith_Signed(t *testing.T) {
+	for i, test := range formatSizeWithSignedTests {
+		s := FormatSizeWith(test.Size, test.Fmt, test.Prec, test.Opts)
+		if s != test.String {
+			t.Fatalf("Test %d: got %s - want %s", i, s, test.String)
+		}
+	}
+}
+
+var formatSizeWithSignedTests = []struct {
+	Size   Size
+	Fmt    byte
+	Prec   int
+	Opts   FormatOptions
+	String string
+}{
+	{Size: 250 * MB, Fmt: 'D', Prec: -1, Opts: FormatOptions{Signed: true}, String: "+250MB"},                       // 0
+	{Size: -1200 * MB, Fmt: 'D', Prec: -1, Opts: FormatOptions{Signed: true}, String: "-1.2GB"},                     // 1
+	{Size: 0, Fmt: 'D', Prec: -1, Opts: FormatOptions{Signed: true}, String: "0B"},                                  // 2
+	{Size: 1, Fmt: 'b', Prec: -1, Opts: FormatOptions{Signed: true}, String: "+1b"},                                 // 3
+	{Size: 1500 * KB, Fmt: 'D', Prec: 3, Opts: FormatOptions{Signed: true, Compact: true}, String: "+1.5MB"},        // 4
+	{Size: 500, Fmt: 'D', Prec: -1, Opts: FormatOptions{Signed: true, MinUnit: KB}, String: "+0.5KB"},               // 5
+	{Size: math.MaxInt64, Fmt: 'D', Prec: -1, Opts: FormatOptions{Signed: true}, String: "+9.223372036854775807EB"}, // 6
+	{Size: KB, Fmt: 'x', Prec: -1, Opts: FormatOptions{Signed: true}, String: "%x"},                                 // 7
+	{Size: 250 * MB, Fmt: 'D', Prec: -1, Opts: FormatOptions{Signed: false}, String: "250MB"},                       // 8
+}
+
+var parseTrimTests = []struct {
+	String     string
+	Size       Size
+	BitSize    BitSize
+	ShouldFail bool
+}{
+	{String: "0B", Size: 0},                 // 0
+	{String: " 0B", Size: 0},                // 1
+	{String: "5MB\n", Size: 5 * MB},         // 2
+	{String: "\t-1.5KiB \r\n", Size: -1536}, // 3
+	{String: " 8Mbit ", BitSize: 8 * MBit},  // 4
+	{String: "", ShouldFail: true},          // 5
+	{String: " \t", ShouldFail: true},       // 6
+	{String: " 5  MB ", ShouldFail: true},   // 7
+}
+
+func TestParseTrim(t *testing.T) {
+	for i, test := range parseTrimTests {
+		size, err := ParseSizeTrim(test.String)
+		bitsize, bitErr := ParseBitSizeTrim(test.String)
+		if test.ShouldFail {
+			if err == nil || bitErr == nil {
+				t.Fatalf("Test %d should have failed", i)
+			}
+			continue
+		}
+		if err != nil && bitErr != nil {
+			t.Fatalf("Test %d: failed to parse: %v", i, err)
+		}
+		if err == nil && size != test.Size {
+			t.Fatalf("Test %d: got '%d (%s)' - want %d (%s)", i, size, size, test.Size, test.Size)
+		}
+		if bitErr == nil && bitsize != test.BitSize {
+			t.Fatalf("Test %d: got '%d (%s)' - want %d (%s)", i, bitsize, bitsize, test.BitSize, test.BitSize)
+		}
+	}
+}
+
+func TestFormatNegativePrecision(t *testing.T) {
+	sizes := []Size{0, Byte, -Byte, 1*MB + 111*KB, -1*MB - 111*KB, 1*GiB + 512*MiB, 1999, math.MaxInt64, math.MinInt64}
+	for _, prec := range []int{-2, -100, math.MinInt} {
+		for i, s := range sizes {
+			for _, f := range []byte{'d', 'D', 'b', 'B'} {
+				if got, want := FormatSize(s, f, prec), FormatSize(s, f, -1); got != want {
+					t.Fatalf("Test %d: format '%c' with prec %d: got %s - want %s", i, f, prec, got, want)
+				}
+			}
+			b := s.Bits()
+			if got, want := FormatBitSize(b, 'D', prec), FormatBitSize(b, 'D', -1); got != want {
+				t.Fatalf("Test %d: format 'D' with prec %d: got %s - want %s", i, prec, got, want)
+			}
+			if got, want := FormatBandwidth(Bandwidth(b), 'D', prec), FormatBandwidth(Bandwidth(b), 'D', -1); got != want {
+				t.Fatalf("Test %d: format 'D' with prec %d: got %s - want %s", i, prec, got, want)
+			}
+		}
+	}
+}
+
+func TestFormatSizeAligned(t *testing.T) {
+	for i, test := range formatSizeAlignedTests {
+		if s := FormatSizeAligned(test.Size, test.Width); s != test.String {
+			t.Fatalf("Test %d: got '%s' - want '%s'", i, s, test.String)
+		}
+	}
+}
+
+var formatSizeAlignedTests = []struct {
+	Size   Size
+	Width  int
+	String string
+}{
+	{Size: 1500 * MB, Width: 7, String: "  1.5GB"},         // 0
+	{Size: 234 * MB, Width: 7, String: "234.0MB"},          // 1
+	{Size: 12, Width: 7, String: " 12.0B "},                // 2
+	{Size: 0, Width: 7, String: "  0.0B "},                 // 3
+	{Size: -1500 * KB, Width: 8, String: "  -1.5MB"},       // 4
+	{Size: 1500 * MB, Width: 0, String: "1.5GB"},           // 5
+	{Size: 12, Width: 0, String: "12.0B "},                 // 6
+	{Size: 999999, Width: 7, String: "1000.0KB"},           // 7
+	{Size: math.MaxInt64, Width: 10, String: "     9.2EB"}, // 8
+	{Size: 0, Width: 0, String: "0.0B "},                   // 9
+}
+
+func TestFormatSizeReport(t *testing.T) {
+	for i, test := range formatSizeReportTests {
+		if r := FormatSizeReport(test.Entries); r != test.Report {
+			t.Fatalf("Test %d: got\n%s\n- want\n%s", i, r, test.Report)
+		}
+	}
+}
+
+var formatSizeReportTests = []struct {
+	Entries map[string]Size
+	Report  string
+}{
+	{ // 0
+		Entries: nil,
+		Report:  "total  0B\n",
+	},
+	{ // 1
+		Entries: map[string]Size{"photos": 12500 * MB, "backups": 1250 * MB},
+		Report: "backups   1.25GB\n" +
+			"photos   12.50GB\n" +
+			"total    13.75GB\n",
+	},
+	{ // 2
+		Entries: map[string]Size{"a": 512, "b": 12},
+		Report: "a      512B\n" +
+			"b       12B\n" +
+			"total  524B\n",
+	},
+	{ // 3
+		Entries: map[string]Size{"tmp": -2 * MB, "cache": 500 * KB},
+		Report: "cache   0.50MB\n" +
+			"tmp    -2.00MB\n" +
+			"total  -1.50MB\n",
+	},
+	{ // 4
+		Entries: map[string]Size{"a": math.MaxInt64, "b": 1},
+		Report: "a      9.22EB\n" +
+			"b      0.00EB\n" +
+			"total  9.22EB\n",
+	},
+	{ // 5
+		Entries: map[string]Size{"total": 2 * KB, "a": 1 * KB},
+		Report: "a      1.00KB\n" +
+			"total  2.00KB\n" +
+			"total  3.00KB\n",
+	},
+}
+
+func TestFormatRatio(t *testing.T) {
+	for i, test := range formatRatioTests {
+		if r := FormatRatio(test.A, test.B); r != test.Ratio {
+			t.Fatalf("Test %d: got %s - want %s", i, r, test.Ratio)
+		}
+	}
+}
+
+var formatRatioTests = []struct {
+	A, B  Size
+	Ratio string
+}{
+	{A: 3200 * MB, B: GB, Ratio: "3.2:1"},                      // 0
+	{A: GB, B: GB, Ratio: "1.0:1"},                             // 1
+	{A: GB, B: 5 * GB, Ratio: "0.2:1"},                         // 2
+	{A: 10 * GB, B: 3 * GB, Ratio: "3.3:1"},                    // 3
+	{A: 0, B: GB, Ratio: "0.0:1"},                              // 4
+	{A: GB, B: 0, Ratio: "n/a"},                                // 5
+	{A: 0, B: 0, Ratio: "n/a"},                                 // 6
+	{A: math.MaxInt64, B: 1, Ratio: "9223372036854775808.0:1"}, // 7
+}
+
+func TestSizeFromEnv(t *testing.T) {
+	const Key = "MEM_TEST_SIZE_FROM_ENV"
+	for i, test := range sizeFromEnvTests {
+		if test.Set {
+			t.Setenv(Key, test.Value)
+		} else {
+			os.Unsetenv(Key)
+		}
+
+		size, err := SizeFromEnv(Key, test.Default)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to parse Size: %v", i, err)
+		}
+		if err != nil {
+			if !strings.Contains(err.Error(), Key) {
+				t.Fatalf("Test %d: error does not contain the key: %v", i, err)
+			}
+			continue
+		}
+		if size != test.Size {
+			t.Fatalf("Test %d: got %d - want %d", i, size, test.Size)
+		}
+	}
+}
+
+var sizeFromEnvTests = []struct {
+	Value      string
+	Set        bool
+	Default    Size
+	Size       Size
+	ShouldFail bool
+}{
+	{Set: false, Default: 5 * MiB, Size: 5 * MiB},                    // 0
+	{Value: "", Set: true, Default: 5 * MiB, Size: 5 * MiB},          // 1
+	{Value: "1GB", Set: true, Default: 5 * MiB, Size: GB},            // 2
+	{Value: "0B", Set: true, Default: 5 * MiB, Size: 0},              // 3
+	{Value: "5  MiB", Set: true, Default: 5 * MiB, ShouldFail: true}, // 4
+	{Value: "5", Set: true, Default: 5 * MiB, ShouldFail: true},      // 5
+}
+
+func TestParseSizeWith(t *testing.T) {
+	units := map[string]Size{
+		"blk":  4 * KiB,
+		"B":    Byte,
+		"page": PageSize,
+		"zero": 0,
+		"neg":  -KB,
+	}
+	for i, test := range parseSizeWithTests {
+		size, err := ParseSizeWith(test.String, units)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to parse Size: %v", i, err)
+		}
+		if err != nil {
+			continue
+		}
+		if size != test.Size {
+			t.Fatalf("Test %d: got '%d (%s)' - want %d (%s)", i, size, size, test.Size, test.Size)
+		}
+	}
+}
+
+var parseSizeWithTests = []struct {
+	String     string
+	Size       Size
+	ShouldFail bool
+}{
+	{String: "1blk", Size: 4 * KiB},                   // 0
+	{String: "1.5blk", Size: 6 * KiB},                 // 1
+	{String: "-2page", Size: -8 * KiB},                // 2
+	{String: "512B", Size: 512},                       // 3
+	{String: "1,024blk", Size: 4 * MiB},               // 4
+	{String: "1KB", ShouldFail: true},                 // 5
+	{String: "1BLK", ShouldFail: true},                // 6
+	{String: "1zero", ShouldFail: true},               // 7
+	{String: "1neg", ShouldFail: true},                // 8
+	{String: "blk", ShouldFail: true},                 // 9
+	{String: "1", ShouldFail: true},                   // 10
+	{String: "9999999999999999blk", ShouldFail: true}, // 11
+}
+
+func TestParseNetSize(t *testing.T) {
+	for i, test := range parseNetSizeTests {
+		size, err := ParseNetSize(test.String)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to parse BitSize: %v", i, err)
+		}
+		if err != nil {
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Test %d: got error '%v' - want *ParseError", i, err)
+			}
+			if perr.Type != "bit size" {
+				t.Fatalf("Test %d: got type '%s' - want 'bit size'", i, perr.Type)
+			}
+			continue
+		}
+		if size != test.Size {
+			t.Fatalf("Test %d: got '%d (%s)' - want %d (%s)", i, size, size, test.Size, test.Size)
+		}
+	}
+}
+
+var parseNetSizeTests = []struct {
+	String     string
+	Size       BitSize
+	ShouldFail bool
+}{
+	{String: "0b", Size: 0},                   // 0
+	{String: "1b", Size: Bit},                 // 1
+	{String: "1B", Size: 8 * Bit},             // 2
+	{String: "100Mb", Size: 100 * MBit},       // 3
+	{String: "100MB", Size: 800 * MBit},       // 4
+	{String: "1.5kb", Size: 1500 * Bit},       // 5
+	{String: "1.5KB", Size: 12 * KBit},        // 6
+	{String: "-1Gb", Size: -GBit},             // 7
+	{String: "1Tb", Size: TBit},               // 8
+	{String: "1Pb", Size: 1000 * TBit},        // 9
+	{String: "1KiB", Size: 8192 * Bit},        // 10
+	{String: "1Mib", Size: 1 << 20},           // 11
+	{String: "1024PiB", ShouldFail: true},     // 12
+	{String: "1023PiB", Size: 1023 * 8 << 50}, // 13
+	{String: "1mb", ShouldFail: true},         // 14
+	{String: "1MIB", ShouldFail: true},        // 15
+	{String: "1Mbit", ShouldFail: true},       // 16
+	{String: "1M", ShouldFail: true},          // 17
+	{String: "1", ShouldFail: true},           // 18
+	{String: "b", ShouldFail: true},           // 19
+}
+
+func TestCheckFormat(t *testing.T) {
+	for i, test := range checkFormatTests {
+		if valid := ValidFormat(test.Fmt); valid != test.Valid {
+			t.Fatalf("Test %d: got %v - want %v", i, valid, test.Valid)
+		}
+
+		err := CheckFormat(test.Fmt, test.Prec)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to check format: %v", i, err)
+		}
+		if err == nil && strings.HasPrefix(FormatSize(KB, test.Fmt, test.Prec), "%") {
+			t.Fatalf("Test %d: valid format produces malformed string", i)
+		}
+	}
+}
+
+var checkFormatTests = []struct {
+	Fmt        byte
+	Prec       int
+	Valid      bool
+	ShouldFail bool
+}{
+	{Fmt: 'd', Prec: -1, Valid: true},                    // 0
+	{Fmt: 'D', Prec: 0, Valid: true},                     // 1
+	{Fmt: 'b', Prec: 2, Valid: true},                     // 2
+	{Fmt: 'B', Prec: 20, Valid: true},                    // 3
+	{Fmt: 'B', Prec: -2, Valid: true, ShouldFail: true},  // 4
+	{Fmt: 'x', Prec: -1, Valid: false, ShouldFail: true}, // 5
+	{Fmt: 'n', Prec: -1, Valid: false, ShouldFail: true}, // 6
+	{Fmt: 0, Prec: -1, Valid: false, ShouldFail: true},   // 7
+}
+
+func TestParseBandwidth(t *testing.T) {
+	for i, test := range parseBandwidthTests {
+		b, err := ParseBandwidth(test.String)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to parse bandwidth: %v", i, err)
+		}
+		if b != test.Bandwidth {
+			t.Fatalf("Test %d: got %d - want %d", i, b, test.Bandwidth)
+		}
+	}
+}
+
+var parseBandwidthTests = []struct {
+	String     string
+	Bandwidth  Bandwidth
+	ShouldFail bool
+}{
+	{String: "0B/s", Bandwidth: 0},                         // 0
+	{String: "0.125B/s", Bandwidth: BitPerSecond},          // 1
+	{String: "1MB/s", Bandwidth: MBytePerSecond},           // 2
+	{String: "-1mb/s", Bandwidth: -MBytePerSecond},         // 3
+	{String: "1.25GB/s", Bandwidth: 1250 * MBytePerSecond}, // 4
+	{String: "1MiB/s", Bandwidth: 1 << 20 * BytePerSecond}, // 5
+	{String: "125KB/s", Bandwidth: MBitPerSecond},          // 6
+	{String: "1MB", ShouldFail: true},                      // 7
+	{String: "1/s", ShouldFail: true},                      // 8
+	{String: "MB/s", ShouldFail: true},                     // 9
+	{String: "1MB/h", ShouldFail: true},                    // 10
+	{String: "", ShouldFail: true},                         // 11
+	{String: "1bit/s", Bandwidth: BitPerSecond},            // 12
+	{String: "100Mbit/s", Bandwidth: 100 * MBitPerSecond},  // 13
+	{String: "1.5gbit/s", Bandwidth: 1500 * MBitPerSecond}, // 14
+	{String: "-8Kbit/s", Bandwidth: -KBytePerSecond},       // 15
+	{String: "10Tbit/s", Bandwidth: 10 * TBitPerSecond},    // 16
+	{String: "0.001Kbit/s", Bandwidth: BitPerSecond},       // 17
+	{String: "100Mbit", ShouldFail: true},                  // 18
+	{String: "1nibble/s", ShouldFail: true},                // 19
+	{String: "1Mbit/S", ShouldFail: true},                  // 20
+	{String: "1Pbit/s", Bandwidth: PBitPerSecond},          // 21
+	{String: "1EB/s", Bandwidth: EBytePerSecond},           // 22
+	{String: "1EiB/s", ShouldFail: true},                   // 23
+}
+
+func TestParseError(t *testing.T) {
+	for i, test := range parseErrorTests {
+		var err error
+		if test.Bits {
+			_, err = ParseBitSize(test.String)
+		} else {
+			_, err = ParseSize(test.String)
+		}
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("Test %d: got error '%v' - want *ParseError", i, err)
+		}
+		if !errors.Is(err, test.Err) {
+			t.Fatalf("Test %d: got error '%v' - want %v", i, err, test.Err)
+		}
+		if perr.Input != test.String {
+			t.Fatalf("Test %d: got input '%s' - want '%s'", i, perr.Input, test.String)
+		}
+		if perr.Part != test.Part {
+			t.Fatalf("Test %d: got part '%s' - want '%s'", i, perr.Part, test.Part)
+		}
+		if err.Error() != test.Message {
+			t.Fatalf("Test %d: got message '%s' - want '%s'", i, err.Error(), test.Message)
+		}
+	}
+}
+
+func TestParseErrorVariants(t *testing.T) {
+	for i, test := range parseErrorVariantTests {
+		_, err := test.Parse(test.String)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("Test %d: got error '%v' - want *ParseError", i, err)
+		}
+		if !errors.Is(err, test.Err) {
+			t.Fatalf("Test %d: got error '%v' - want %v", i, err, test.Err)
+		}
+		if perr.Input != test.String {
+			t.Fatalf("Test %d: got input '%s' - want '%s'", i, perr.Input, test.String)
+		}
+	}
+}
+
+var parseErrorVariantTests = []struct {
+	Parse  func(string) (Size, error)
+	String string
+	Err    error
+}{
+	{Parse: ParseSizeFlexible, String: "   ", Err: ErrSyntax},                       // 0
+	{Parse: ParseSizeFlexible, String: "MB", Err: ErrSyntax},                        // 1
+	{Parse: ParseSizeFlexible, String: " XB5", Err: ErrSyntax},                      // 2
+	{Parse: ParseSizeFlexible, String: " 10000PB ", Err: ErrRange},                  // 3
+	{Parse: ParseSizeFlexible, String: "PB -10000", Err: ErrRange},                  // 4
+	{Parse: ParseSizeFraction, String: "1/2XB", Err: ErrSyntax},                     // 5
+	{Parse: ParseSizeFraction, String: "1/0GB", Err: ErrSyntax},                     // 6
+	{Parse: ParseSizeFraction, String: "/2GB", Err: ErrSyntax},                      // 7
+	{Parse: ParseSizeFraction, String: "8192/1PiB", Err: ErrRange},                  // 8
+	{Parse: ParseSizeFraction, String: "36893488147419103232/1B", Err: ErrRange},    // 9
+	{Parse: ParseSizeFraction, String: "1/36893488147419103232B", Err: ErrRange},    // 10
+	{Parse: ParseSizeFraction, String: "18428297329635842064/999KB", Err: ErrRange}, // 11
+	{Parse: ParseSizeFraction, String: "10000PB", Err: ErrRange},                    // 12
+	{Parse: ParseSizeStrict, String: "007KB", Err: ErrSyntax},                       // 13
+	{Parse: ParseSizeStrict, String: "-.5KB", Err: ErrSyntax},                       // 14
+	{Parse: ParseSizeStrict, String: "5.KB", Err: ErrSyntax},                        // 15
+	{Parse: ParseSizeStrict, String: "KB", Err: ErrSyntax},                          // 16
+	{Parse: ParseSizeStrict, String: "10000PB", Err: ErrRange},                      // 17
+}
+
+var parseErrorTests = []struct {
+	String  string
+	Bits    bool
+	Err     error
+	Part    string
+	Message string
+}{
+	{String: "", Err: ErrSyntax, Part: "", Message: "mem: invalid size '': invalid syntax"},                                                                 // 0
+	{String: "5XB", Err: ErrSyntax, Part: "XB", Message: "mem: invalid size '5XB': invalid syntax"},                                                         // 1
+	{String: "5", Err: ErrSyntax, Part: "", Message: "mem: invalid size '5': invalid syntax"},                                                               // 2
+	{String: "1.5mbit", Err: ErrSyntax, Part: "mbit", Message: "mem: invalid size '1.5mbit': invalid syntax"},                                               // 3
+	{String: "10000PB", Err: ErrRange, Part: "10000", Message: "mem: invalid size '10000PB': value out of range"},                                           // 4
+	{String: "-9300PB", Err: ErrRange, Part: "9300", Message: "mem: invalid size '-9300PB': value out of range"},                                            // 5
+	{String: "99999999999999999999B", Err: ErrRange, Part: "9999999999999999999", Message: "mem: invalid size '99999999999999999999B': value out of range"}, // 6
+	{String: "9223372036854775808B", Err: ErrRange, Part: "9223372036854775808", Message: "mem: invalid size '9223372036854775808B': value out of range"},   // 7
+	{String: "", Bits: true, Err: ErrSyntax, Part: "", Message: "mem: invalid bit size '': invalid syntax"},                                                 // 8
+	{String: "1.5MB", Bits: true, Err: ErrSyntax, Part: "MB", Message: "mem: invalid bit size '1.5MB': invalid syntax"},                                     // 9
+	{String: "Mbit", Bits: true, Err: ErrSyntax, Part: "Mbit", Message: "mem: invalid bit size 'Mbit': invalid syntax"},                                     // 10
+	{String: "9300000Tbit", Bits: true, Err: ErrRange, Part: "9300000", Message: "mem: invalid bit size '9300000Tbit': value out of range"},                 // 11
+	{String: "9.5EB", Err: ErrRange, Part: "9.5", Message: "mem: invalid size '9.5EB': value out of range"},                                                 // 12
+	{String: "-9.5EB", Err: ErrRange, Part: "9.5", Message: "mem: invalid size '-9.5EB': value out of range"},                                               // 13
+	{String: "9.5Ebit", Bits: true, Err: ErrRange, Part: "9.5", Message: "mem: invalid bit size '9.5Ebit': value out of range"},                             // 14
+	{String: "1e20B", Err: ErrRange, Part: "1e20", Message: "mem: invalid size '1e20B': value out of range"},                                                // 15
+	{String: "-9.3e18B", Err: ErrRange, Part: "9300000000000000000", Message: "mem: invalid size '-9.3e18B': value out of range"},                           // 16
+	{String: "1,0e2B", Err: ErrSyntax, Part: "1,0e2", Message: "mem: invalid size '1,0e2B': invalid syntax"},                                                // 17
+	{String: "9.3e6Tbit", Bits: true, Err: ErrRange, Part: "9300000", Message: "mem: invalid bit size '9.3e6Tbit': value out of range"},                     // 18
+	{String: "1e99bit", Bits: true, Err: ErrRange, Part: "1e99", Message: "mem: invalid bit size '1e99bit': value out of range"},                            // 19
+	{String: "8XB", Bits: true, Err: ErrSyntax, Part: "XB", Message: "mem: invalid bit size '8XB': invalid syntax"},                                         // 20
+	{String: "1.5", Bits: true, Err: ErrSyntax, Part: "", Message: "mem: invalid bit size '1.5': invalid syntax"},                                           // 21
+	{String: "-1.5Ebits", Bits: true, Err: ErrSyntax, Part: "Ebits", Message: "mem: invalid bit size '-1.5Ebits': invalid syntax"},                          // 22
+	{String: "-9.3Ebit", Bits: true, Err: ErrRange, Part: "9.3", Message: "mem: invalid bit size '-9.3Ebit': value out of range"},                           // 23
+	{String: ".e3bit", Bits: true, Err: ErrSyntax, Part: ".e3", Message: "mem: invalid bit size '.e3bit': invalid syntax"},                                  // 24
+	{String: "1,000bit", Bits: true, Err: ErrSyntax, Part: ",000bit", Message: "mem: invalid bit size '1,000bit': invalid syntax"},                          // 25
+	{String: "5 Mbit", Bits: true, Err: ErrSyntax, Part: " Mbit", Message: "mem: invalid bit size '5 Mbit': invalid syntax"},                                // 26
+}
+
+var parseBitSizeTests = []struct {
+	String     string
+	Size       BitSize
+	ShouldFail bool
+}{
+	{String: "8bit", Size: 8 * Bit},                          // 0
+	{String: "1.5Mbit", Size: 1500 * KBit},                   // 1
+	{String: "1.5e3Mbit", Size: 1500 * MBit},                 // 2
+	{String: "-25E-1Kbit", Size: -2500 * Bit},                // 3
+	{String: "1e0nibble", Size: Nibble},                      // 4
+	{String: "1ebit", Size: EBit},                            // 5
+	{String: "1e3", ShouldFail: true},                        // 6
+	{String: "1e+bit", ShouldFail: true},                     // 7
+	{String: "1e19Ebit", ShouldFail: true},                   // 8
+	{String: "5 Mbit", ShouldFail: true},                     // 9
+	{String: "1,000bit", ShouldFail: true},                   // 10
+	{String: "-9223372036854775808bit", Size: math.MinInt64}, // 11
+	{String: "5  Mbit", ShouldFail: true},                    // 12
+	{String: "1,00bit", ShouldFail: true},                    // 13
+}
+
+func TestParseBitSize(t *testing.T) {
+	for i, test := range parseBitSizeTests {
+		size, err := ParseBitSize(test.String)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to parse BitSize: %v", i, err)
+		}
+		if err == nil && size != test.Size {
+			t.Fatalf("Test %d: got '%d (%s)' - want %d (%s)", i, size, size, test.Size, test.Size)
+		}
+	}
+}
+
+func TestFormatSizeIn(t *testing.T) {
+	for i, test := range formatSizeInTests {
+		s := FormatSizeIn(test.Size, test.Unit, test.Prec, test.Upper)
+		if s != test.String {
+			t.Fatalf("Test %d: got %s - want %s", i, s, test.String)
+		}
+	}
+}
+
+var formatSizeInTests = []struct {
+	Size   Size
+	Unit   Size
+	Prec   int
+	Upper  bool
+	String string
+}{
+	{Size: 1500 * MB, Unit: MiB, Prec: 1, Upper: true, String: "1430.5MiB"}, // 0
+	{Size: 512 * KiB, Unit: MiB, Prec: -1, Upper: true, String: "0.5MiB"},   // 1
+	{Size: 2 * GB, Unit: MB, Prec: 0, Upper: false, String: "2000mb"},       // 2
+	{Size: -1500, Unit: KB, Prec: 2, Upper: true, String: "-1.50KB"},        // 3
+	{Size: 0, Unit: KiB, Prec: -1, Upper: false, String: "0kib"},            // 4
+	{Size: 5 * MB, Unit: Byte, Prec: -1, Upper: true, String: "5000000B"},   // 5
+	{Size: 5 * MB, Unit: 0, Prec: -1, Upper: true, String: "5MB"},           // 6
+	{Size: 5 * MB, Unit: 3, Prec: -1, Upper: false, String: "5mb"},          // 7
+}
+
+func TestFormatBitSizeIn(t *testing.T) {
+	for i, test := range formatBitSizeInTests {
+		s := FormatBitSizeIn(test.Size, test.Unit, test.Prec, test.Upper)
+		if s != test.String {
+			t.Fatalf("Test %d: got %s - want %s", i, s, test.String)
+		}
+	}
+}
+
+var formatBitSizeInTests = []struct {
+	Size   BitSize
+	Unit   BitSize
+	Prec   int
+	Upper  bool
+	String string
+}{
+	{Size: 1500 * KBit, Unit: MBit, Prec: -1, Upper: true, String: "1.5Mbit"},    // 0
+	{Size: 2 * GBit, Unit: KBit, Prec: 1, Upper: false, String: "2000000.0kbit"}, // 1
+	{Size: 12, Unit: Nibble, Prec: -1, Upper: true, String: "3Nibble"},           // 2
+	{Size: 8, Unit: Bit, Prec: -1, Upper: true, String: "8Bit"},                  // 3
+	{Size: 1500 * KBit, Unit: 7, Prec: 2, Upper: false, String: "1.50mbit"},      // 4
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import (
+	"math"
+	"sort"
+)
+
+// NewSizeHistogram returns a new SizeHistogram with the given bucket
+// boundaries. The n boundaries define n+1 buckets. For example, the
+// boundaries 1KB and 1MB define the three buckets:
+//
+//	< 1KB, 1KB - 1MB and >= 1MB
+//
+// Each bucket includes its lower and excludes its upper boundary.
+// The boundaries do not have to be sorted. Duplicate boundaries
+// are ignored.
+func NewSizeHistogram(bounds ...Size) *SizeHistogram {
+	b := make([]Size, 0, len(bounds))
+	b = append(b, bounds...)
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+
+	// Remove duplicates from the sorted boundaries.
+	n := 0
+	for i := range b {
+		if i == 0 || b[i] != b[n-1] {
+			b[n] = b[i]
+			n++
+		}
+	}
+	b = b[:n]
+
+	return &SizeHistogram{
+		bounds: b,
+		counts: make([]uint64, len(b)+1),
+	}
+}
+
+// SizeHistogram counts sizes in buckets of size ranges, for example
+// to report the distribution of file sizes within a directory tree.
+//
+// A SizeHistogram must not be used concurrently by multiple goroutines.
+type SizeHistogram struct {
+	bounds []Size
+	counts []uint64
+}
+
+// SizeBucket is a range of sizes of a SizeHistogram and the number
+// of sizes within this range.
+type SizeBucket struct {
+	// Min is the smallest size within the bucket.
+	Min Size
+
+	// Max is the smallest size greater than Min that is not
+	// within the bucket. As a special case, the Max of the
+	// last bucket is math.MaxInt64 and within the bucket.
+	Max Size
+
+	// Count is the number of sizes added to the histogram
+	// that are within the bucket.
+	Count uint64
+
+	// Label is a human-readable description of the bucket's
+	// size range, like "1KB - 1MB", "< 1KB" or ">= 1MB".
+	Label string
+}
+
+// Add adds the size s to the bucket that contains s.
+func (h *SizeHistogram) Add(s Size) {
+	i := sort.Search(len(h.bounds), func(i int) bool { return h.bounds[i] > s })
+	h.counts[i]++
+}
+
+// Buckets returns the buckets of the histogram, ordered
+// from the smallest to the largest size range.
+func (h *SizeHistogram) Buckets() []SizeBucket {
+	buckets := make([]SizeBucket, 0, len(h.counts))
+	for i, count := range h.counts {
+		var bucket SizeBucket
+		switch {
+		case len(h.bounds) == 0:
+			bucket.Min, bucket.Max = math.MinInt64, math.MaxInt64
+			bucket.Label = "all"
+		case i == 0:
+			bucket.Min, bucket.Max = math.MinInt64, h.bounds[0]
+			bucket.Label = "< " + bucket.Max.String()
+		case i == len(h.bounds):
+			bucket.Min, bucket.Max = h.bounds[i-1], math.MaxInt64
+			bucket.Label = ">= " + bucket.Min.String()
+		default:
+			bucket.Min, bucket.Max = h.bounds[i-1], h.bounds[i]
+			bucket.Label = bucket.Min.String() + " - " + bucket.Max.String()
+		}
+		bucket.Count = count
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSizeHistogram(t *testing.T) {
+	for i, test := range sizeHistogramTests {
+		h := NewSizeHistogram(test.Bounds...)
+		for _, s := range test.Sizes {
+			h.Add(s)
+		}
+
+		buckets := h.Buckets()
+		if len(buckets) != len(test.Buckets) {
+			t.Fatalf("Test %d: got %d buckets - want %d", i, len(buckets), len(test.Buckets))
+		}
+		for j, bucket := range buckets {
+			if bucket != test.Buckets[j] {
+				t.Fatalf("Test %d: bucket %d: got %+v - want %+v", i, j, bucket, test.Buckets[j])
+			}
+		}
+	}
+}
+
+var sizeHistogramTests = []struct {
+	Bounds  []Size
+	Sizes   []Size
+	Buckets []SizeBucket
+}{
+	{ // 0
+		Bounds: nil,
+		Sizes:  []Size{0, MB},
+		Buckets: []SizeBucket{
+			{Min: math.MinInt64, Max: math.MaxInt64, Count: 2, Label: "all"},
+		},
+	},
+	{ // 1
+		Bounds: []Size{0, KB, MB},
+		Sizes:  []Size{0, 1, KB - 1, KB, 512 * KB, MB, GB, math.MaxInt64},
+		Buckets: []SizeBucket{
+			{Min: math.MinInt64, Max: 0, Count: 0, Label: "< 0B"},
+			{Min: 0, Max: KB, Count: 3, Label: "0B - 1KB"},
+			{Min: KB, Max: MB, Count: 2, Label: "1KB - 1MB"},
+			{Min: MB, Max: math.MaxInt64, Count: 3, Label: ">= 1MB"},
+		},
+	},
+	{ // 2
+		Bounds: []Size{MiB, KiB, MiB},
+		Sizes:  []Size{-1, 2 * KiB, 2 * MiB},
+		Buckets: []SizeBucket{
+			{Min: math.MinInt64, Max: KiB, Count: 1, Label: "< 1.024KB"},
+			{Min: KiB, Max: MiB, Count: 1, Label: "1.024KB - 1.048576MB"},
+			{Min: MiB, Max: math.MaxInt64, Count: 1, Label: ">= 1.048576MB"},
+		},
+	},
+}
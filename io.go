@@ -4,13 +4,159 @@
 
 package mem
 
-import "io"
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrLimitExceeded is returned by readers that limit the
+// amount of data that can be read once more data than
+// permitted is available.
+var ErrLimitExceeded = errors.New("mem: read limit exceeded")
 
 // LimitReader returns a io.LimitedReader that reads from r
-// but stops with io.EOF after n bytes.
+// but stops with io.EOF after n bytes. Use MaxBytesReader to
+// distinguish the end of r from reaching the limit.
 func LimitReader(r io.Reader, n Size) *io.LimitedReader {
 	return &io.LimitedReader{
 		R: r,
 		N: int64(n),
 	}
 }
+
+// MaxBytesReader returns an io.Reader that reads from r but at
+// most n bytes, similar to http.MaxBytesReader. Once r provides
+// more than n bytes, it returns the first n bytes and then fails
+// with ErrLimitExceeded. Reading exactly n bytes from r does not
+// fail. For example, to reject oversized uploads:
+//
+//	_, err := io.Copy(dst, mem.MaxBytesReader(conn, 32*mem.MiB))
+//	if errors.Is(err, mem.ErrLimitExceeded) {
+//		// The upload is larger than 32MiB
+//	}
+//
+// It is equivalent to GuardedReader(r, n, 0).
+func MaxBytesReader(r io.Reader, n Size) io.Reader {
+	return GuardedReader(r, n, 0)
+}
+
+// CopyRate copies from src to dst until either EOF is reached
+// on src or an error occurs, like io.Copy. It returns the number
+// of bytes copied, the average bandwidth of the copy and the first
+// error encountered while copying, if any. For example:
+//
+//	n, rate, err := mem.CopyRate(dst, src)
+//	fmt.Printf("copied %v at %v\n", n, rate)
+//
+// CopyRate uses io.Copy and, therefore, preserves the fast paths
+// of io.WriterTo and io.ReaderFrom. On error, the returned size
+// and bandwidth refer to the bytes copied before the error.
+func CopyRate(dst io.Writer, src io.Reader) (n Size, rate Bandwidth, err error) {
+	start := time.Now()
+	c, err := io.Copy(dst, src)
+	return Size(c), Throughput(Size(c), time.Since(start)), err
+}
+
+// PadReader returns an io.Reader that reads exactly n bytes.
+// It reads from r but stops with io.EOF after n bytes. If r
+// returns io.EOF before n bytes have been read, the returned
+// reader pads the remaining bytes with zeros.
+//
+// Any error other than io.EOF returned by r is returned
+// as is.
+func PadReader(r io.Reader, n Size) io.Reader {
+	return &padReader{
+		R: r,
+		N: int64(n),
+	}
+}
+
+type padReader struct {
+	R   io.Reader
+	N   int64 // Remaining bytes
+	EOF bool  // R has returned io.EOF
+}
+
+func (p *padReader) Read(b []byte) (int, error) {
+	if p.N <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(b)) > p.N {
+		b = b[:p.N]
+	}
+
+	if !p.EOF {
+		n, err := p.R.Read(b)
+		p.N -= int64(n)
+		if err != io.EOF {
+			return n, err
+		}
+		p.EOF = true
+		if n > 0 {
+			return n, nil
+		}
+	}
+
+	for i := range b {
+		b[i] = 0
+	}
+	p.N -= int64(len(b))
+	return len(b), nil
+}
+
+// GuardedReader returns an io.Reader that reads from r but at most
+// totalMax bytes in total and at most perReadMax bytes per Read.
+// Once r provides more than totalMax bytes, it returns the first
+// totalMax bytes and then fails with ErrLimitExceeded. Reading
+// exactly totalMax bytes from r does not fail.
+//
+// GuardedReader protects against inputs, like decompression bombs,
+// that expand to an unexpected large amount of data. In contrast to
+// io.LimitReader, it does not silently truncate the input. If
+// perReadMax <= 0, reads are only limited by totalMax.
+func GuardedReader(r io.Reader, totalMax, perReadMax Size) io.Reader {
+	if totalMax < 0 {
+		totalMax = 0
+	}
+	return &guardedReader{
+		R:       r,
+		N:       int64(totalMax),
+		PerRead: int64(perReadMax),
+	}
+}
+
+type guardedReader struct {
+	R       io.Reader
+	N       int64 // Remaining bytes
+	PerRead int64 // Max. bytes per Read; no limit if <= 0
+	Err     error // Sticky ErrLimitExceeded
+}
+
+func (g *guardedReader) Read(b []byte) (int, error) {
+	if g.Err != nil {
+		return 0, g.Err
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	if g.PerRead > 0 && int64(len(b)) > g.PerRead {
+		b = b[:g.PerRead]
+	}
+	// Try to read one byte more than remaining such that
+	// we can detect whether r contains too much data.
+	if int64(len(b))-1 > g.N {
+		b = b[:g.N+1]
+	}
+
+	n, err := g.R.Read(b)
+	if int64(n) > g.N {
+		n = int(g.N)
+		g.N = 0
+		g.Err = ErrLimitExceeded
+		return n, g.Err
+	}
+	g.N -= int64(n)
+	return n, err
+}
@@ -5,9 +5,16 @@
 package mem
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"os"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestProgress_Done(t *testing.T) {
@@ -27,3 +34,561 @@ var progressDoneTests = []struct {
 	{Progress: Progress{Err: io.EOF}, Done: true},
 	{Progress: Progress{Err: fmt.Errorf("wrapped %w", io.EOF)}, Done: true},
 }
+
+func TestProgress_Percent(t *testing.T) {
+	for i, test := range progressPercentTests {
+		if percent := test.Progress.Percent(); percent != test.Percent {
+			t.Fatalf("Test %d: got %f - want %f", i, percent, test.Percent)
+		}
+	}
+}
+
+var progressPercentTests = []struct {
+	Progress Progress
+	Percent  float64
+}{
+	{Progress: Progress{Total: MB, Expected: 0}, Percent: -1},            // 0
+	{Progress: Progress{Total: MB, Expected: -MB}, Percent: -1},          // 1
+	{Progress: Progress{Total: 0, Expected: 4 * MB}, Percent: 0},         // 2
+	{Progress: Progress{Total: MB, Expected: 4 * MB}, Percent: 25},       // 3
+	{Progress: Progress{Total: 4 * MB, Expected: 4 * MB}, Percent: 100},  // 4
+	{Progress: Progress{Total: 5 * MB, Expected: 4 * MB}, Percent: 100},  // 5
+	{Progress: Progress{Total: -MB, Expected: 4 * MB}, Percent: 0},       // 6
+	{Progress: Progress{Total: 3 * MB, Expected: 8 * MB}, Percent: 37.5}, // 7
+}
+
+func TestProgress_ETA(t *testing.T) {
+	for i, test := range progressETATests {
+		if eta := test.Progress.ETA(); eta != test.ETA {
+			t.Fatalf("Test %d: got %v - want %v", i, eta, test.ETA)
+		}
+	}
+}
+
+var progressETATests = []struct {
+	Progress Progress
+	ETA      time.Duration
+}{
+	{Progress: Progress{Total: MB, Expected: 0, Rate: MBytePerSecond}, ETA: -1},                        // 0
+	{Progress: Progress{Total: MB, Expected: 2 * MB, Rate: 0}, ETA: -1},                                // 1
+	{Progress: Progress{Total: MB, Expected: 2 * MB, Rate: MBytePerSecond}, ETA: time.Second},          // 2
+	{Progress: Progress{Total: MB, Expected: 11 * MB, Rate: 2 * MBytePerSecond}, ETA: 5 * time.Second}, // 3
+	{Progress: Progress{Total: 2 * MB, Expected: 2 * MB, Rate: MBytePerSecond}, ETA: 0},                // 4
+	{Progress: Progress{Total: 3 * MB, Expected: 2 * MB, Rate: MBytePerSecond}, ETA: 0},                // 5
+	{Progress: Progress{Total: 0, Expected: 8 * MB, Rate: MBitPerSecond}, ETA: 64 * time.Second},       // 6
+	{Progress: Progress{Total: 0, Expected: math.MaxInt64, Rate: BitPerSecond}, ETA: math.MaxInt64},    // 7
+}
+
+func TestProgressReader_Expected(t *testing.T) {
+	var progress []Progress
+	r := NewProgressReader(bytes.NewReader(make([]byte, 10*KB)), 0, func(p Progress) {
+		progress = append(progress, p)
+	})
+	r.Expected = 10 * KB
+	if rate := r.Rate(); rate != 0 {
+		t.Fatalf("got rate %v before first read - want 0", rate)
+	}
+
+	time.Sleep(time.Millisecond)
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if rate := r.Rate(); rate <= 0 {
+		t.Fatalf("got rate %v after reading - want > 0", rate)
+	}
+	for i, p := range progress {
+		if p.Expected != r.Expected {
+			t.Fatalf("Update %d: got expected %v - want %v", i, p.Expected, r.Expected)
+		}
+	}
+	if last := progress[len(progress)-1]; last.ETA() != 0 {
+		t.Fatalf("got final ETA %v - want 0", last.ETA())
+	}
+}
+
+func TestProgressReader_SkipFinalUpdate(t *testing.T) {
+	for i, test := range progressSkipFinalUpdateTests {
+		var r io.Reader = bytes.NewReader(test.Data)
+		if test.Fail {
+			r = io.MultiReader(r, errReader{})
+		}
+
+		var updates int
+		p := &ProgressReader{
+			R:               r,
+			SkipFinalUpdate: test.Skip,
+			Update: func(p Progress) {
+				if p.Done() && test.Skip {
+					t.Fatalf("Test %d: final update has been sent", i)
+				}
+				updates++
+			},
+		}
+		_, err := io.Copy(io.Discard, p)
+		if test.Fail && !errors.Is(err, errReadFailed) {
+			t.Fatalf("Test %d: got error '%v' - want '%v'", i, err, errReadFailed)
+		}
+		if !test.Fail && err != nil {
+			t.Fatalf("Test %d: failed to read: %v", i, err)
+		}
+		if updates != test.Updates {
+			t.Fatalf("Test %d: got %d updates - want %d", i, updates, test.Updates)
+		}
+	}
+}
+
+var progressSkipFinalUpdateTests = []struct {
+	Data    []byte
+	Fail    bool // Fail after reading Data
+	Skip    bool
+	Updates int
+}{
+	{Data: make([]byte, 1), Skip: false, Updates: 2},            // 0
+	{Data: make([]byte, 1), Skip: true, Updates: 1},             // 1
+	{Data: nil, Skip: true, Updates: 0},                         // 2
+	{Data: make([]byte, 1), Fail: true, Skip: true, Updates: 2}, // 3
+}
+
+var errReadFailed = errors.New("read failed")
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errReadFailed }
+
+func TestPadReader(t *testing.T) {
+	for i, test := range padReaderTests {
+		data, err := io.ReadAll(PadReader(bytes.NewReader(test.Data), test.N))
+		if err != nil {
+			t.Fatalf("Test %d: failed to read: %v", i, err)
+		}
+		if !bytes.Equal(data, test.Output) {
+			t.Fatalf("Test %d: got %x - want %x", i, data, test.Output)
+		}
+	}
+
+	_, err := io.ReadAll(PadReader(errReader{}, 8))
+	if err == nil {
+		t.Fatal("Read error has not been returned")
+	}
+}
+
+var padReaderTests = []struct {
+	Data   []byte
+	N      Size
+	Output []byte
+}{
+	{Data: nil, N: 0, Output: []byte{}},                                                                                 // 0
+	{Data: nil, N: 3, Output: []byte{0, 0, 0}},                                                                          // 1
+	{Data: []byte{1, 2}, N: 4, Output: []byte{1, 2, 0, 0}},                                                              // 2
+	{Data: []byte{1, 2, 3, 4}, N: 4, Output: []byte{1, 2, 3, 4}},                                                        // 3
+	{Data: []byte{1, 2, 3, 4, 5, 6}, N: 4, Output: []byte{1, 2, 3, 4}},                                                  // 4
+	{Data: []byte{1, 2}, N: -1, Output: []byte{}},                                                                       // 5
+	{Data: bytes.Repeat([]byte{1}, 1000), N: 1500, Output: append(bytes.Repeat([]byte{1}, 1000), make([]byte, 500)...)}, // 6
+}
+
+func TestProgressReader_Rate(t *testing.T) {
+	p := &ProgressReader{
+		R: bytes.NewReader(make([]byte, 1*MB)),
+	}
+	if rate := p.Progress().Rate; rate != 0 {
+		t.Fatalf("got rate %v before first read - want 0", rate)
+	}
+
+	start := time.Now()
+	buf := make([]byte, 64*KB)
+	for i := 0; i < 4; i++ {
+		time.Sleep(5 * time.Millisecond)
+		if _, err := p.Read(buf); err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+	}
+	rate := p.Progress().Rate
+
+	// The first read happens after the first sleep. Hence, at
+	// least 15ms have passed between the first and last read.
+	min, max := Throughput(256*KB, time.Since(start)), Throughput(256*KB, 15*time.Millisecond)
+	if rate < min || rate > max {
+		t.Fatalf("got rate %v - want rate in [%v, %v]", rate, min, max)
+	}
+}
+
+func TestProgressReader_SetReadDeadline(t *testing.T) {
+	p := &ProgressReader{R: bytes.NewReader(nil)}
+	if err := p.SetReadDeadline(time.Now()); !errors.Is(err, os.ErrNoDeadline) {
+		t.Fatalf("got error %v - want %v", err, os.ErrNoDeadline)
+	}
+
+	deadline := time.Now().Add(time.Minute)
+	r := &deadlineReader{}
+	p = &ProgressReader{R: r}
+	if err := p.SetReadDeadline(deadline); err != nil {
+		t.Fatalf("failed to set read deadline: %v", err)
+	}
+	if !r.Deadline.Equal(deadline) {
+		t.Fatalf("got deadline %v - want %v", r.Deadline, deadline)
+	}
+}
+
+type deadlineReader struct {
+	Deadline time.Time
+}
+
+func (*deadlineReader) Read([]byte) (int, error) { return 0, io.EOF }
+
+func (r *deadlineReader) SetReadDeadline(t time.Time) error {
+	r.Deadline = t
+	return nil
+}
+
+func TestProgressReader_Reset(t *testing.T) {
+	var last Progress
+	p := &ProgressReader{
+		Update: func(p Progress) { last = p },
+	}
+	for i, size := range []Size{1 * KB, 0, 3 * KB, 512} {
+		p.Reset(bytes.NewReader(make([]byte, size)))
+		if n, err := io.Copy(io.Discard, p); err != nil || Size(n) != size {
+			t.Fatalf("Test %d: failed to read %v: got %d bytes - err: %v", i, size, n, err)
+		}
+		if !last.Done() {
+			t.Fatalf("Test %d: operation is not done: %v", i, last.Err)
+		}
+		if last.Total != size {
+			t.Fatalf("Test %d: got total %v - want %v", i, last.Total, size)
+		}
+	}
+	if want := 4*KB + 512; last.GrandTotal != want {
+		t.Fatalf("got grand total %v - want %v", last.GrandTotal, want)
+	}
+}
+
+func TestProgressReader_ResetAll(t *testing.T) {
+	var updates []Progress
+	pool := sync.Pool{
+		New: func() any {
+			return &ProgressReader{
+				MinUpdateInterval: time.Hour,
+				Update:            func(p Progress) { updates = append(updates, p) },
+			}
+		},
+	}
+
+	for i, size := range []Size{3 * KB, 512, 0, KB} {
+		p := pool.Get().(*ProgressReader)
+		p.ResetAll(bytes.NewReader(make([]byte, size)))
+
+		updates = updates[:0]
+		buf := make([]byte, 256)
+		if _, err := p.Read(buf); size > 0 && err != nil {
+			t.Fatalf("Test %d: failed to read: %v", i, err)
+		}
+		if len(updates) != 1 {
+			t.Fatalf("Test %d: got %d updates after first read - want 1", i, len(updates))
+		}
+		if first := updates[0]; first.GrandTotal != first.Total {
+			t.Fatalf("Test %d: got grand total %v - want %v", i, first.GrandTotal, first.Total)
+		}
+		if n, err := io.Copy(io.Discard, p); err != nil || Size(n)+updates[0].Total != size {
+			t.Fatalf("Test %d: failed to read %v: err: %v", i, size, err)
+		}
+		if last := updates[len(updates)-1]; last.GrandTotal != size || !last.Done() {
+			t.Fatalf("Test %d: got final progress %+v - want grand total %v", i, last, size)
+		}
+		pool.Put(p)
+	}
+}
+
+func TestProgressReader_Context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var updates []Progress
+	p := &ProgressReader{
+		R:       bytes.NewReader(make([]byte, 4*KB)),
+		Context: ctx,
+		Update:  func(p Progress) { updates = append(updates, p) },
+	}
+
+	buf := make([]byte, KB)
+	if n, err := p.Read(buf); err != nil || n != len(buf) {
+		t.Fatalf("Failed to read: got %d bytes - err: %v", n, err)
+	}
+	cancel()
+	if n, err := p.Read(buf); n != 0 || !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %d bytes and error %v - want 0 bytes and %v", n, err, context.Canceled)
+	}
+	if _, err := p.Read(buf); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v - want %v", err, context.Canceled)
+	}
+
+	if len(updates) != 2 {
+		t.Fatalf("got %d updates - want 2", len(updates))
+	}
+	if last := updates[1]; !errors.Is(last.Err, context.Canceled) || last.Total != KB || last.Done() {
+		t.Fatalf("got final progress %+v - want total %v and error %v", last, KB, context.Canceled)
+	}
+}
+
+func TestProgressReader_PercentString(t *testing.T) {
+	for i, test := range progressPercentStringTests {
+		p := &ProgressReader{R: bytes.NewReader(make([]byte, test.Read)), Expected: test.Expected}
+		if _, err := io.Copy(io.Discard, p); err != nil {
+			t.Fatalf("Test %d: failed to read: %v", i, err)
+		}
+		if s := p.PercentString(test.Total); s != test.Percent {
+			t.Fatalf("Test %d: got %s - want %s", i, s, test.Percent)
+		}
+	}
+}
+
+var progressPercentStringTests = []struct {
+	Read     Size
+	Total    Size
+	Expected Size
+	Percent  string
+}{
+	{Read: 0, Total: KB, Percent: "0.0%"},                   // 0
+	{Read: 375, Total: KB, Percent: "37.5%"},                // 1
+	{Read: KB, Total: KB, Percent: "100.0%"},                // 2
+	{Read: 2 * KB, Total: KB, Percent: "100.0%"},            // 3
+	{Read: 333, Total: KB, Percent: "33.3%"},                // 4
+	{Read: 0, Total: 0, Percent: "n/a"},                     // 5
+	{Read: KB, Total: -1, Percent: "n/a"},                   // 6
+	{Read: 250, Total: 0, Expected: KB, Percent: "25.0%"},   // 7
+	{Read: 250, Total: 500, Expected: KB, Percent: "50.0%"}, // 8
+	{Read: 250, Total: -1, Expected: -1, Percent: "n/a"},    // 9
+}
+
+func TestGuardedReader(t *testing.T) {
+	for i, test := range guardedReaderTests {
+		r := &chunkReader{R: bytes.NewReader(make([]byte, test.Size))}
+		data, err := io.ReadAll(GuardedReader(r, test.TotalMax, test.PerReadMax))
+		if test.Exceeded && !errors.Is(err, ErrLimitExceeded) {
+			t.Fatalf("Test %d: got error '%v' - want '%v'", i, err, ErrLimitExceeded)
+		}
+		if !test.Exceeded && err != nil {
+			t.Fatalf("Test %d: failed to read: %v", i, err)
+		}
+		if Size(len(data)) != test.Read {
+			t.Fatalf("Test %d: got %d bytes - want %d", i, len(data), test.Read)
+		}
+		if test.PerReadMax > 0 && r.Max > int(test.PerReadMax) {
+			t.Fatalf("Test %d: read %d bytes at once - want at most %d", i, r.Max, test.PerReadMax)
+		}
+	}
+}
+
+var guardedReaderTests = []struct {
+	Size       Size
+	TotalMax   Size
+	PerReadMax Size
+	Read       Size
+	Exceeded   bool
+}{
+	{Size: 0, TotalMax: 0, PerReadMax: 0, Read: 0},                             // 0
+	{Size: 1, TotalMax: 0, PerReadMax: 0, Read: 0, Exceeded: true},             // 1
+	{Size: MB, TotalMax: MB, PerReadMax: 0, Read: MB},                          // 2
+	{Size: MB + 1, TotalMax: MB, PerReadMax: 0, Read: MB, Exceeded: true},      // 3
+	{Size: MB, TotalMax: MB, PerReadMax: KB, Read: MB},                         // 4
+	{Size: 2 * MB, TotalMax: MB, PerReadMax: KB, Read: MB, Exceeded: true},     // 5
+	{Size: 10 * KB, TotalMax: MB, PerReadMax: 100, Read: 10 * KB},              // 6
+	{Size: 10 * KB, TotalMax: -1, PerReadMax: 0, Read: 0, Exceeded: true},      // 7
+	{Size: 999, TotalMax: 1000, PerReadMax: 1000, Read: 999},                   // 8
+	{Size: 1001, TotalMax: 1000, PerReadMax: 1000, Read: 1000, Exceeded: true}, // 9
+}
+
+func TestCopyRate(t *testing.T) {
+	var dst bytes.Buffer
+	n, rate, err := CopyRate(&dst, bytes.NewReader(make([]byte, MB)))
+	if err != nil {
+		t.Fatalf("Failed to copy: %v", err)
+	}
+	if n != MB || Size(dst.Len()) != MB {
+		t.Fatalf("got %d bytes - want %d", n, MB)
+	}
+	if rate <= 0 {
+		t.Fatalf("got rate %v - want a positive rate", rate)
+	}
+
+	errWrite := errors.New("write failed")
+	n, _, err = CopyRate(&failingWriter{N: KB, Err: errWrite}, bytes.NewReader(make([]byte, MB)))
+	if !errors.Is(err, errWrite) {
+		t.Fatalf("got error '%v' - want '%v'", err, errWrite)
+	}
+	if n != KB {
+		t.Fatalf("got %d bytes - want %d", n, KB)
+	}
+}
+
+// failingWriter accepts N bytes and then fails with Err.
+type failingWriter struct {
+	N   Size
+	Err error
+}
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	if Size(len(b)) > w.N {
+		n := int(w.N)
+		w.N = 0
+		return n, w.Err
+	}
+	w.N -= Size(len(b))
+	return len(b), nil
+}
+
+func TestMaxBytesReader(t *testing.T) {
+	for i, test := range maxBytesReaderTests {
+		data, err := io.ReadAll(MaxBytesReader(bytes.NewReader(make([]byte, test.Size)), test.Max))
+		if test.Exceeded && !errors.Is(err, ErrLimitExceeded) {
+			t.Fatalf("Test %d: got error '%v' - want '%v'", i, err, ErrLimitExceeded)
+		}
+		if !test.Exceeded && err != nil {
+			t.Fatalf("Test %d: failed to read: %v", i, err)
+		}
+		if Size(len(data)) != test.Read {
+			t.Fatalf("Test %d: got %d bytes - want %d", i, len(data), test.Read)
+		}
+	}
+}
+
+var maxBytesReaderTests = []struct {
+	Size     Size
+	Max      Size
+	Read     Size
+	Exceeded bool
+}{
+	{Size: 0, Max: 0, Read: 0},                        // 0
+	{Size: KB, Max: MB, Read: KB},                     // 1
+	{Size: MB, Max: MB, Read: MB},                     // 2
+	{Size: MB + 1, Max: MB, Read: MB, Exceeded: true}, // 3
+	{Size: 1, Max: 0, Read: 0, Exceeded: true},        // 4
+	{Size: 1, Max: -1, Read: 0, Exceeded: true},       // 5
+}
+
+// chunkReader wraps an io.Reader and records the size
+// of the largest read.
+type chunkReader struct {
+	R   io.Reader
+	Max int
+}
+
+func (r *chunkReader) Read(b []byte) (int, error) {
+	if len(b) > r.Max {
+		r.Max = len(b)
+	}
+	return r.R.Read(b)
+}
+
+func TestProgressReader_Invariants(t *testing.T) {
+	for i, test := range progressInvariantsTests {
+		var (
+			last    Progress
+			updates int
+		)
+		check := func(p Progress) {
+			if p.N < 0 || p.N > p.Total || p.Total > p.GrandTotal {
+				t.Fatalf("Test %d: invalid progress: N=%d Total=%d GrandTotal=%d", i, p.N, p.Total, p.GrandTotal)
+			}
+			if p.Total < last.Total || p.GrandTotal < last.GrandTotal {
+				t.Fatalf("Test %d: total decreased: got %d - previous %d", i, p.Total, last.Total)
+			}
+			last = p
+			updates++
+		}
+
+		r := &ProgressReader{
+			R:           &stepReader{Steps: test.Steps},
+			Update:      check,
+			UpdateAfter: test.UpdateAfter,
+		}
+		buf := make([]byte, 64)
+		for {
+			_, err := r.Read(buf)
+			check(r.Progress())
+			if err != nil {
+				break
+			}
+		}
+
+		var total Size
+		for _, n := range test.Steps {
+			total += Size(abs(int64(n)))
+		}
+		if last.Total != total {
+			t.Fatalf("Test %d: got total %d - want %d", i, last.Total, total)
+		}
+		if !last.Done() {
+			t.Fatalf("Test %d: operation is not done: %v", i, last.Err)
+		}
+	}
+}
+
+var progressInvariantsTests = []struct {
+	Steps       []int
+	UpdateAfter Size
+}{
+	{Steps: nil},                                  // 0
+	{Steps: []int{0, 0, 0}},                       // 1
+	{Steps: []int{64, 0, 1, 63, 0, 32}},           // 2
+	{Steps: []int{1, 2, 3, 0, 5}, UpdateAfter: 4}, // 3
+	{Steps: []int{64, 64, -1}, UpdateAfter: 100},  // 4
+	{Steps: []int{0, 17, -17}},                    // 5
+}
+
+// stepReader returns n bytes per Read for each n in Steps. If n is
+// negative, it returns -n bytes and io.EOF. Once all steps have been
+// processed, it returns io.EOF.
+type stepReader struct {
+	Steps []int
+}
+
+func (r *stepReader) Read(b []byte) (int, error) {
+	if len(r.Steps) == 0 {
+		return 0, io.EOF
+	}
+	n := r.Steps[0]
+	r.Steps = r.Steps[1:]
+	if n < 0 {
+		return -n, io.EOF
+	}
+	return n, nil
+}
+
+func TestProgressReader_MinUpdateInterval(t *testing.T) {
+	for i, test := range progressMinUpdateIntervalTests {
+		var (
+			updates int
+			last    Progress
+		)
+		r := &ProgressReader{
+			R:                 bytes.NewReader(make([]byte, 1*KB)),
+			Update:            func(p Progress) { updates++; last = p },
+			UpdateAfter:       test.UpdateAfter,
+			MinUpdateInterval: test.MinUpdateInterval,
+		}
+		buf := make([]byte, 10)
+		for {
+			if _, err := r.Read(buf); err != nil {
+				break
+			}
+		}
+		if updates != test.Updates {
+			t.Fatalf("Test %d: got %d updates - want %d", i, updates, test.Updates)
+		}
+		if !last.Done() || last.Total != KB {
+			t.Fatalf("Test %d: invalid final update: total %d - err: %v", i, last.Total, last.Err)
+		}
+	}
+}
+
+var progressMinUpdateIntervalTests = []struct {
+	UpdateAfter       Size
+	MinUpdateInterval time.Duration
+	Updates           int
+}{
+	{UpdateAfter: 0, MinUpdateInterval: 0, Updates: 101},         // 0
+	{UpdateAfter: 0, MinUpdateInterval: time.Hour, Updates: 2},   // 1
+	{UpdateAfter: 100, MinUpdateInterval: 0, Updates: 11},        // 2
+	{UpdateAfter: 100, MinUpdateInterval: time.Hour, Updates: 2}, // 3
+	{UpdateAfter: 0, MinUpdateInterval: -1, Updates: 101},        // 4
+}
@@ -4,7 +4,10 @@
 
 package mem
 
-import "math"
+import (
+	"math"
+	"math/bits"
+)
 
 func abs(v int64) int64 {
 	switch {
@@ -17,6 +20,157 @@ func abs(v int64) int64 {
 	}
 }
 
+// absDiff returns |x - y|. If the difference exceeds math.MaxInt64,
+// absDiff returns math.MaxInt64.
+func absDiff(x, y int64) int64 {
+	var d uint64
+	if x >= y {
+		d = uint64(x) - uint64(y)
+	} else {
+		d = uint64(y) - uint64(x)
+	}
+	if d > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(d)
+}
+
+// add returns x + y. If the sum exceeds the max. resp. min.
+// int64 value, add returns math.MaxInt64 resp. math.MinInt64.
+func add(x, y int64) int64 {
+	s, _ := addOverflow(x, y)
+	return s
+}
+
+// addOverflow returns x + y like add and reports whether
+// the sum has been saturated since it overflowed.
+func addOverflow(x, y int64) (int64, bool) {
+	s := x + y
+	switch {
+	case y > 0 && s < x:
+		return math.MaxInt64, true
+	case y < 0 && s > x:
+		return math.MinInt64, true
+	default:
+		return s, false
+	}
+}
+
+// sub returns x - y. If the difference exceeds the max. resp. min.
+// int64 value, sub returns math.MaxInt64 resp. math.MinInt64.
+func sub(x, y int64) int64 {
+	d, _ := subOverflow(x, y)
+	return d
+}
+
+// subOverflow returns x - y like sub and reports whether
+// the difference has been saturated since it overflowed.
+func subOverflow(x, y int64) (int64, bool) {
+	d := x - y
+	switch {
+	case y < 0 && d < x:
+		return math.MaxInt64, true
+	case y > 0 && d > x:
+		return math.MinInt64, true
+	default:
+		return d, false
+	}
+}
+
+// mul returns x * y. If the product exceeds the max. resp. min.
+// int64 value, mul returns math.MaxInt64 resp. math.MinInt64.
+func mul(x, y int64) int64 {
+	p, _ := mulOverflow(x, y)
+	return p
+}
+
+// mulOverflow returns x * y like mul and reports whether
+// the product has been saturated since it overflowed.
+func mulOverflow(x, y int64) (int64, bool) {
+	if x == 0 || y == 0 {
+		return 0, false
+	}
+	neg := (x < 0) != (y < 0)
+	hi, lo := bits.Mul64(uint64(x), uint64(y))
+	if x < 0 {
+		hi -= uint64(y)
+	}
+	if y < 0 {
+		hi -= uint64(x)
+	}
+	// The signed 128 bit product fits into 64 bits if and only if
+	// hi is the sign extension of lo.
+	if int64(hi) != int64(lo)>>63 {
+		return saturate(neg), true
+	}
+	return int64(lo), false
+}
+
+// fraction returns r / l * unit rounded to the nearest integer,
+// where r < l. The rounding behavior for halfway values is to round
+// away from zero.
+//
+// It computes r * unit / l exactly using integer arithmetic. Rounding
+// to the nearest integer ensures that fractions formatted as shortest
+// floating point representation, like "1.0000000000000008881784197001252PiB",
+// are parsed exactly although they are not the exact decimal fraction.
+func fraction(r, l, unit uint64) uint64 {
+	hi, lo := bits.Mul64(r, unit)
+	q, rem := bits.Div64(hi, lo, l) // r < l, hence hi < l
+	if rem >= l-rem {
+		q++
+	}
+	return q
+}
+
+// rescale returns v / from * to rounded to the nearest integer.
+// The rounding behavior for halfway values is to round away from
+// zero. If the result exceeds the max. resp. min. int64 value,
+// rescale returns math.MaxInt64 resp. math.MinInt64.
+//
+// Both, from and to, must be greater than zero.
+func rescale(v, from, to int64) int64 {
+	neg := v < 0
+	u := uint64(v)
+	if neg {
+		u = -u // Two's complement - works for math.MinInt64, too
+	}
+	m, r := u/uint64(from), u%uint64(from)
+
+	hi, lo := bits.Mul64(m, uint64(to))
+	if hi != 0 {
+		return saturate(neg)
+	}
+	hi, rlo := bits.Mul64(r, uint64(to))
+	q, rem := bits.Div64(hi, rlo, uint64(from)) // r < from, hence hi < from
+	if rem >= uint64(from)-rem {
+		q++ // Round halfway values away from zero
+	}
+	res, carry := bits.Add64(lo, q, 0)
+	if carry != 0 {
+		return saturate(neg)
+	}
+	switch {
+	case neg && res > 1<<63:
+		return math.MinInt64
+	case neg:
+		return -int64(res)
+	case res > math.MaxInt64:
+		return math.MaxInt64
+	default:
+		return int64(res)
+	}
+}
+
+// saturate returns math.MinInt64 if neg is true and
+// math.MaxInt64 otherwise.
+func saturate(neg bool) int64 {
+	if neg {
+		return math.MinInt64
+	}
+	return math.MaxInt64
+}
+
 func truncate(v, m int64) int64 {
 	if m <= 0 {
 		return v
@@ -31,7 +185,7 @@ func round(v, m int64) int64 {
 	r := v % m
 	if v < 0 {
 		r = -r
-		if lessThanHalf(v, m) {
+		if lessThanHalf(r, m) {
 			return v + r
 		}
 		if v1 := v - m + r; v1 < v {
@@ -29,6 +29,139 @@ var absTests = []struct {
 	{Size: math.MinInt64 + 1, Abs: math.MaxInt64}, // 5
 }
 
+func TestAbsDiff(t *testing.T) {
+	for i, test := range absDiffTests {
+		if d := absDiff(test.X, test.Y); d != test.Diff {
+			t.Fatalf("Test %d: got %d - want %d", i, d, test.Diff)
+		}
+	}
+}
+
+var absDiffTests = []struct {
+	X, Y int64
+	Diff int64
+}{
+	{X: 0, Y: 0, Diff: 0},                                     // 0
+	{X: 5, Y: 3, Diff: 2},                                     // 1
+	{X: 3, Y: 5, Diff: 2},                                     // 2
+	{X: -3, Y: 5, Diff: 8},                                    // 3
+	{X: math.MaxInt64, Y: 0, Diff: math.MaxInt64},             // 4
+	{X: math.MinInt64 + 1, Y: 0, Diff: math.MaxInt64},         // 5
+	{X: math.MaxInt64, Y: -1, Diff: math.MaxInt64},            // 6
+	{X: math.MinInt64, Y: math.MaxInt64, Diff: math.MaxInt64}, // 7
+	{X: math.MinInt64, Y: math.MinInt64 + 5, Diff: 5},         // 8
+	{X: math.MaxInt64, Y: math.MaxInt64 - 10, Diff: 10},       // 9
+}
+
+func TestAdd(t *testing.T) {
+	for i, test := range addTests {
+		if s := add(test.X, test.Y); s != test.Sum {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test.Sum)
+		}
+		if s := add(test.Y, test.X); s != test.Sum {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test.Sum)
+		}
+	}
+}
+
+var addTests = []struct {
+	X, Y int64
+	Sum  int64
+}{
+	{X: 0, Y: 0, Sum: 0},                                     // 0
+	{X: 5, Y: -3, Sum: 2},                                    // 1
+	{X: math.MaxInt64, Y: 1, Sum: math.MaxInt64},             // 2
+	{X: math.MaxInt64, Y: math.MinInt64, Sum: -1},            // 3
+	{X: math.MinInt64, Y: -1, Sum: math.MinInt64},            // 4
+	{X: math.MinInt64, Y: math.MinInt64, Sum: math.MinInt64}, // 5
+	{X: math.MaxInt64 - 1, Y: 1, Sum: math.MaxInt64},         // 6
+}
+
+func TestSub(t *testing.T) {
+	for i, test := range subTests {
+		if d := sub(test.X, test.Y); d != test.Diff {
+			t.Fatalf("Test %d: got %d - want %d", i, d, test.Diff)
+		}
+	}
+}
+
+var subTests = []struct {
+	X, Y int64
+	Diff int64
+}{
+	{X: 0, Y: 0, Diff: 0},                                         // 0
+	{X: 5, Y: 3, Diff: 2},                                         // 1
+	{X: 3, Y: 5, Diff: -2},                                        // 2
+	{X: math.MaxInt64, Y: -1, Diff: math.MaxInt64},                // 3
+	{X: math.MinInt64, Y: 1, Diff: math.MinInt64},                 // 4
+	{X: 0, Y: math.MinInt64, Diff: math.MaxInt64},                 // 5
+	{X: -1, Y: math.MinInt64, Diff: math.MaxInt64},                // 6
+	{X: math.MinInt64, Y: math.MinInt64, Diff: 0},                 // 7
+	{X: math.MaxInt64, Y: math.MaxInt64, Diff: 0},                 // 8
+	{X: math.MinInt64 + 1, Y: math.MaxInt64, Diff: math.MinInt64}, // 9
+}
+
+func TestMul(t *testing.T) {
+	for i, test := range mulTests {
+		if p := mul(test.X, test.Y); p != test.Product {
+			t.Fatalf("Test %d: got %d - want %d", i, p, test.Product)
+		}
+		if p := mul(test.Y, test.X); p != test.Product {
+			t.Fatalf("Test %d: got %d - want %d", i, p, test.Product)
+		}
+	}
+}
+
+var mulTests = []struct {
+	X, Y    int64
+	Product int64
+}{
+	{X: 0, Y: 0, Product: 0},                              // 0
+	{X: 3, Y: 5, Product: 15},                             // 1
+	{X: -3, Y: 5, Product: -15},                           // 2
+	{X: -3, Y: -5, Product: 15},                           // 3
+	{X: math.MaxInt64, Y: 1, Product: math.MaxInt64},      // 4
+	{X: math.MaxInt64, Y: -1, Product: math.MinInt64 + 1}, // 5
+	{X: math.MinInt64, Y: 1, Product: math.MinInt64},      // 6
+	{X: math.MinInt64, Y: -1, Product: math.MaxInt64},     // 7
+	{X: math.MinInt64, Y: 0, Product: 0},                  // 8
+	{X: math.MaxInt64, Y: 2, Product: math.MaxInt64},      // 9
+	{X: math.MaxInt64, Y: -2, Product: math.MinInt64},     // 10
+	{X: math.MinInt64 / 2, Y: 2, Product: math.MinInt64},  // 11
+	{X: math.MinInt64 / 2, Y: -2, Product: math.MaxInt64}, // 12
+	{X: 1 << 32, Y: 1 << 31, Product: math.MaxInt64},      // 13
+	{X: 1 << 32, Y: -(1 << 31), Product: math.MinInt64},   // 14
+	{X: 1 << 32, Y: 1<<31 - 1, Product: 1<<63 - 1<<32},    // 15
+}
+
+func TestRescale(t *testing.T) {
+	for i, test := range rescaleTests {
+		if v := rescale(test.V, test.From, test.To); v != test.Result {
+			t.Fatalf("Test %d: got %d - want %d", i, v, test.Result)
+		}
+	}
+}
+
+var rescaleTests = []struct {
+	V, From, To int64
+	Result      int64
+}{
+	{V: 0, From: 1000, To: 1024, Result: 0},                                       // 0
+	{V: 1000, From: 1000, To: 1024, Result: 1024},                                 // 1
+	{V: -1000, From: 1000, To: 1024, Result: -1024},                               // 2
+	{V: 1500, From: 1000, To: 1024, Result: 1536},                                 // 3
+	{V: 1024, From: 1024, To: 1000, Result: 1000},                                 // 4
+	{V: 1, From: 2, To: 1, Result: 1},                                             // 5
+	{V: -1, From: 2, To: 1, Result: -1},                                           // 6
+	{V: 1, From: 3, To: 1, Result: 0},                                             // 7
+	{V: math.MaxInt64, From: 1, To: 2, Result: math.MaxInt64},                     // 8
+	{V: math.MinInt64, From: 1, To: 2, Result: math.MinInt64},                     // 9
+	{V: math.MinInt64, From: 2, To: 1, Result: math.MinInt64 / 2},                 // 10
+	{V: math.MaxInt64, From: 1 << 62, To: 1 << 62, Result: math.MaxInt64},         // 11
+	{V: math.MinInt64, From: 1 << 62, To: 1 << 62, Result: math.MinInt64},         // 12
+	{V: math.MaxInt64 / 1000 * 1000, From: 1000, To: 1001, Result: math.MaxInt64}, // 13
+}
+
 func TestTruncate(t *testing.T) {
 	for i, test := range truncateTests {
 		if trunc := truncate(test.Size, test.Mod); trunc != test.Trunc {
@@ -72,6 +205,8 @@ var roundTests = []struct {
 	{Size: 26, Mod: 8, Round: 24},
 	{Size: -999, Mod: 1000, Round: -1000},
 	{Size: -1500, Mod: 1000, Round: -2000},
+	{Size: -1001, Mod: 1000, Round: -1000},
+	{Size: -1499, Mod: 1000, Round: -1000},
 	{Size: 1001, Mod: 1000, Round: 1000},
 	{Size: 1499, Mod: 1000, Round: 1000},
 	{Size: math.MaxInt64, Mod: 2, Round: math.MaxInt64},
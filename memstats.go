@@ -0,0 +1,70 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import (
+	"math"
+	"runtime"
+)
+
+// MemStats contains the commonly used memory allocator statistics
+// of a runtime.MemStats as Size values. See runtime.MemStats for a
+// detailed description of each field.
+type MemStats struct {
+	Alloc      Size // Bytes of allocated heap objects
+	TotalAlloc Size // Cumulative bytes allocated for heap objects
+	Sys        Size // Total bytes of memory obtained from the OS
+
+	HeapAlloc    Size // Bytes of allocated heap objects
+	HeapSys      Size // Bytes of heap memory obtained from the OS
+	HeapIdle     Size // Bytes in idle (unused) spans
+	HeapInuse    Size // Bytes in in-use spans
+	HeapReleased Size // Bytes of physical memory returned to the OS
+
+	StackInuse Size // Bytes in stack spans
+	StackSys   Size // Bytes of stack memory obtained from the OS
+
+	NextGC Size // Target heap size of the next GC cycle
+}
+
+// ReadMemStats returns the current memory allocator statistics.
+// It is a shorthand for calling runtime.ReadMemStats and
+// ConvertMemStats.
+func ReadMemStats() MemStats {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return ConvertMemStats(&stats)
+}
+
+// ConvertMemStats returns the memory allocator statistics of
+// stats as MemStats. Any value that exceeds the max. representable
+// Size is converted to math.MaxInt64.
+func ConvertMemStats(stats *runtime.MemStats) MemStats {
+	return MemStats{
+		Alloc:      sizeOf(stats.Alloc),
+		TotalAlloc: sizeOf(stats.TotalAlloc),
+		Sys:        sizeOf(stats.Sys),
+
+		HeapAlloc:    sizeOf(stats.HeapAlloc),
+		HeapSys:      sizeOf(stats.HeapSys),
+		HeapIdle:     sizeOf(stats.HeapIdle),
+		HeapInuse:    sizeOf(stats.HeapInuse),
+		HeapReleased: sizeOf(stats.HeapReleased),
+
+		StackInuse: sizeOf(stats.StackInuse),
+		StackSys:   sizeOf(stats.StackSys),
+
+		NextGC: sizeOf(stats.NextGC),
+	}
+}
+
+// sizeOf converts v to a Size. As special case, it returns
+// math.MaxInt64 if v is greater than math.MaxInt64.
+func sizeOf(v uint64) Size {
+	if v > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return Size(v)
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import (
+	"math"
+	"runtime"
+	"testing"
+)
+
+func TestConvertMemStats(t *testing.T) {
+	stats := ConvertMemStats(&runtime.MemStats{
+		Alloc:      uint64(5 * MB),
+		TotalAlloc: math.MaxUint64,
+		HeapIdle:   math.MaxInt64 + 1,
+		StackSys:   uint64(64 * KiB),
+	})
+	if stats.Alloc != 5*MB {
+		t.Fatalf("Alloc: got %v - want %v", stats.Alloc, 5*MB)
+	}
+	if stats.TotalAlloc != math.MaxInt64 {
+		t.Fatalf("TotalAlloc: got %d - want %d", stats.TotalAlloc, int64(math.MaxInt64))
+	}
+	if stats.HeapIdle != math.MaxInt64 {
+		t.Fatalf("HeapIdle: got %d - want %d", stats.HeapIdle, int64(math.MaxInt64))
+	}
+	if stats.StackSys != 64*KiB {
+		t.Fatalf("StackSys: got %v - want %v", stats.StackSys, 64*KiB)
+	}
+}
+
+func TestReadMemStats(t *testing.T) {
+	stats := ReadMemStats()
+	if stats.Sys <= 0 {
+		t.Fatalf("Sys: got %v - want > 0", stats.Sys)
+	}
+	if stats.HeapSys > stats.Sys {
+		t.Fatalf("HeapSys %v is greater than Sys %v", stats.HeapSys, stats.Sys)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import "time"
+
+// Meter measures the current and the average rate at which
+// data is transferred, like a speedometer. For example:
+//
+//	var meter mem.Meter
+//	for {
+//		n, err := conn.Read(buf)
+//		meter.Add(mem.Size(n))
+//		fmt.Println(meter.String()) // "12.3MB/s (avg 10.1MB/s)"
+//		...
+//	}
+//
+// A Meter must not be used concurrently by multiple goroutines.
+type Meter struct {
+	// Window is the duration over which the current rate is
+	// measured. If Window <= 0, the current rate is measured
+	// over one second.
+	Window time.Duration
+
+	n, total    Size
+	rate        Bandwidth // Rate of the last complete window
+	full        bool      // At least one window has completed
+	start       time.Time
+	windowStart time.Time
+}
+
+// Add adds n transferred bytes to the meter.
+func (m *Meter) Add(n Size) { m.add(n, time.Now()) }
+
+// Rate returns the current rate. It is the rate measured over
+// the last complete Window or, if no Window has completed yet,
+// since the first call of Add.
+func (m *Meter) Rate() Bandwidth { return m.rateAt(time.Now()) }
+
+// Average returns the average rate since the first call of Add.
+func (m *Meter) Average() Bandwidth { return m.averageAt(time.Now()) }
+
+// Total returns the number of bytes added in total.
+func (m *Meter) Total() Size { return m.total }
+
+// String returns a string representing the current and the
+// average rate in the form "12.3MB/s (avg 10.1MB/s)".
+func (m *Meter) String() string {
+	now := time.Now()
+	return FormatBandwidth(m.rateAt(now), 'D', 1) + " (avg " + FormatBandwidth(m.averageAt(now), 'D', 1) + ")"
+}
+
+func (m *Meter) add(n Size, now time.Time) {
+	if m.start.IsZero() {
+		m.start, m.windowStart = now, now
+	}
+	m.advance(now)
+	m.n += n
+	m.total += n
+}
+
+func (m *Meter) rateAt(now time.Time) Bandwidth {
+	if m.start.IsZero() {
+		return 0
+	}
+	m.advance(now)
+	if !m.full {
+		return Throughput(m.n, now.Sub(m.windowStart))
+	}
+	return m.rate
+}
+
+func (m *Meter) averageAt(now time.Time) Bandwidth {
+	if m.start.IsZero() {
+		return 0
+	}
+	return Throughput(m.total, now.Sub(m.start))
+}
+
+// advance completes the current window if it has ellapsed.
+func (m *Meter) advance(now time.Time) {
+	window := m.Window
+	if window <= 0 {
+		window = time.Second
+	}
+	if d := now.Sub(m.windowStart); d >= window {
+		m.rate = Throughput(m.n, d)
+		m.n = 0
+		m.full = true
+		m.windowStart = now
+	}
+}
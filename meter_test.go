@@ -0,0 +1,82 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMeter(t *testing.T) {
+	for i, test := range meterTests {
+		var (
+			m   = Meter{Window: test.Window}
+			now = time.Unix(0, 0)
+		)
+		for _, s := range test.Samples {
+			now = now.Add(s.After)
+			m.add(s.N, now)
+		}
+		now = now.Add(test.After)
+
+		if rate := m.rateAt(now); rate != test.Rate {
+			t.Fatalf("Test %d: got rate %v - want %v", i, rate, test.Rate)
+		}
+		if avg := m.averageAt(now); avg != test.Average {
+			t.Fatalf("Test %d: got average %v - want %v", i, avg, test.Average)
+		}
+	}
+}
+
+type meterSample struct {
+	After time.Duration
+	N     Size
+}
+
+var meterTests = []struct {
+	Window  time.Duration
+	Samples []meterSample
+	After   time.Duration
+	Rate    Bandwidth
+	Average Bandwidth
+}{
+	{ // 0
+		Samples: nil,
+		Rate:    0,
+		Average: 0,
+	},
+	{ // 1
+		Samples: []meterSample{{0, MB}},
+		After:   500 * time.Millisecond,
+		Rate:    2 * MBytePerSecond,
+		Average: 2 * MBytePerSecond,
+	},
+	{ // 2
+		Samples: []meterSample{{0, MB}, {time.Second, 3 * MB}},
+		After:   500 * time.Millisecond,
+		Rate:    MBytePerSecond,
+		Average: 21333333, // 4MB in 1.5s
+	},
+	{ // 3
+		Samples: []meterSample{{0, MB}, {time.Second, 3 * MB}},
+		After:   time.Second,
+		Rate:    3 * MBytePerSecond,
+		Average: 2 * MBytePerSecond,
+	},
+	{ // 4
+		Window:  100 * time.Millisecond,
+		Samples: []meterSample{{0, 10 * MB}, {50 * time.Millisecond, KB}, {100 * time.Millisecond, KB}},
+		After:   10 * time.Millisecond,
+		Rate:    533386666, // 10001KB in 150ms
+		Average: 500100000, // 10002KB in 160ms
+	},
+}
+
+func TestMeter_String(t *testing.T) {
+	var m Meter
+	if s := m.String(); s != "0B/s (avg 0B/s)" {
+		t.Fatalf("got %s - want %s", s, "0B/s (avg 0B/s)")
+	}
+}
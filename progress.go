@@ -5,13 +5,23 @@
 package mem
 
 import (
+	"context"
 	"errors"
 	"io"
+	"math"
+	"os"
 	"time"
 )
 
 // Progress represents the progress of an I/O operation,
 // like reading data from a file or network connection.
+//
+// The progress reported by a ProgressReader satisfies the
+// following invariants:
+//   - N <= Total <= GrandTotal
+//   - Total never decreases between two updates unless
+//     the ProgressReader is Reset.
+//   - GrandTotal never decreases.
 type Progress struct {
 	// N is the number of bytes since the last progress
 	// update.
@@ -21,6 +31,23 @@ type Progress struct {
 	// of the operation.
 	Total Size
 
+	// GrandTotal is the number of bytes since the start
+	// of the first operation. In contrast to Total, it is
+	// not reset by ProgressReader.Reset and, therefore,
+	// accumulates the bytes of multiple operations, like
+	// transferring multiple files.
+	GrandTotal Size
+
+	// Rate is the average bandwidth of the operation since
+	// its start. It is the Total number of bytes divided by
+	// the time ellapsed since the operation has been started.
+	Rate Bandwidth
+
+	// Expected is the number of bytes the operation is
+	// expected to transfer in total, as specified by
+	// ProgressReader.Expected. It is <= 0 if unknown.
+	Expected Size
+
 	// Err is any error that occurred during the operation.
 	// Once the operation completes, Err is io.EOF.
 	Err error
@@ -29,6 +56,43 @@ type Progress struct {
 // Done reports whether the operation has been completed.
 func (p *Progress) Done() bool { return errors.Is(p.Err, io.EOF) }
 
+// Percent returns the percentage of the Expected number of bytes
+// that have been transferred so far, clamped to [0, 100]. It
+// returns -1 if the expected total is unknown, i.e. Expected <= 0.
+func (p *Progress) Percent() float64 {
+	if p.Expected <= 0 {
+		return -1
+	}
+	switch percent := float64(p.Total) / float64(p.Expected) * 100; {
+	case percent < 0:
+		return 0
+	case percent > 100:
+		return 100
+	default:
+		return percent
+	}
+}
+
+// ETA returns the estimated time remaining until the Expected
+// number of bytes have been transferred at the current Rate.
+// It returns 0 once Total >= Expected and -1 if the remaining
+// time is unknown since either Expected or Rate is <= 0.
+func (p *Progress) ETA() time.Duration {
+	if p.Expected <= 0 || p.Rate <= 0 {
+		return -1
+	}
+	if p.Total >= p.Expected {
+		return 0
+	}
+
+	remaining := float64(p.Expected - p.Total)
+	eta := remaining * float64(BytePerSecond) / float64(p.Rate) * float64(time.Second)
+	if eta >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(eta)
+}
+
 // NewProgressReader returns a new ProgressReader that wraps r and
 // calls update periodically with the current progress while reading.
 func NewProgressReader(r io.Reader, d time.Duration, update func(Progress)) *ProgressReader {
@@ -60,7 +124,8 @@ type ProgressReader struct {
 	// has occurred while reading from R.
 	// Once reading from R returns an non-nil error,
 	// including io.EOF, Update is called immediately
-	// one more time and then never again.
+	// one more time and then never again - unless
+	// SkipFinalUpdate is set.
 	//
 	// Update is called by the goroutine reading from
 	// R. A long-running or blocking Update function
@@ -85,8 +150,54 @@ type ProgressReader struct {
 	// every read.
 	UpdateAfter Size
 
+	// MinUpdateInterval is the minimum duration that has to
+	// ellapse between two Update calls. It limits how often
+	// Update is called, for example to protect an expensive
+	// UI redraw, regardless of UpdateEvery and UpdateAfter.
+	// Bytes read while an Update call is suppressed are
+	// reported by the next Update call.
+	//
+	// MinUpdateInterval does not apply to the final Update
+	// call once reading from R returns an error, including
+	// io.EOF. If MinUpdateInterval <= 0, the frequency of
+	// Update calls is only controlled by UpdateEvery and
+	// UpdateAfter.
+	MinUpdateInterval time.Duration
+
+	// SkipFinalUpdate controls whether Update is called
+	// once reading from R returns io.EOF. If true, Update
+	// is not called when the operation completes and any
+	// bytes read by the final read are not reported.
+	//
+	// Update is still called when reading from R fails
+	// with an error other than io.EOF.
+	SkipFinalUpdate bool
+
+	// Expected is the number of bytes that are expected
+	// to be read from R in total, like the size of a file
+	// that is downloaded. It is passed to Update as part
+	// of the Progress and used to compute the Percent
+	// and ETA.
+	//
+	// If Expected <= 0, the expected total is unknown.
+	Expected Size
+
+	// Context, if non-nil, is checked before every read
+	// from R. Once the Context is canceled or its deadline
+	// is exceeded, Read returns the Context's error, like
+	// context.Canceled, without reading from R. The error
+	// is reported by a final Update call, like any other
+	// error returned by R.
+	//
+	// Context cannot interrupt a read from R that is
+	// already in progress. Use SetReadDeadline for that.
+	Context context.Context
+
 	n, total   Size
+	grandTotal Size
+	start      time.Time
 	lastUpdate time.Time
+	lastCall   time.Time // Last Update call; only set if MinUpdateInterval > 0
 	err        error
 }
 
@@ -94,47 +205,157 @@ func (r *ProgressReader) Read(p []byte) (int, error) {
 	if r.err != nil {
 		return 0, r.err
 	}
+	if r.Context != nil {
+		if err := r.Context.Err(); err != nil {
+			r.err = err
+			if r.Update != nil {
+				r.update(true)
+			}
+			return 0, err
+		}
+	}
+	if r.start.IsZero() {
+		r.start = time.Now()
+	}
 
 	n, err := r.R.Read(p)
 	r.n += Size(n)
 	r.total += Size(n)
+	r.grandTotal += Size(n)
 	if err != nil {
 		r.err = err
 	}
 	if r.Update != nil {
 		switch {
-		case (r.UpdateEvery <= 0 && r.UpdateAfter <= 0) || err != nil:
-			r.Update(r.Progress())
-			r.n = 0
+		case err != nil && r.SkipFinalUpdate && errors.Is(err, io.EOF):
+		case err != nil:
+			r.update(true)
+		case r.UpdateEvery <= 0 && r.UpdateAfter <= 0:
+			r.update(false)
 		case r.UpdateAfter > 0 && r.n >= r.UpdateAfter:
-			r.Update(r.Progress())
-			r.n = 0
+			r.update(false)
 		case r.UpdateEvery > 0 && r.lastUpdate.IsZero():
-			r.Update(r.Progress())
-			r.n = 0
-			r.lastUpdate = time.Now()
+			if r.update(false) {
+				r.lastUpdate = time.Now()
+			}
 		case r.UpdateEvery > 0:
 			now := time.Now()
 			if diff := now.Sub(r.lastUpdate); diff >= r.UpdateEvery {
-				r.Update(r.Progress())
-				r.n = 0
-				r.lastUpdate = now
+				if r.update(false) {
+					r.lastUpdate = now
+				}
 			}
 		}
 	}
 	return n, err
 }
 
+// update calls Update with the current progress unless the
+// MinUpdateInterval has not ellapsed since the last call and
+// final is false. It reports whether Update has been called.
+func (r *ProgressReader) update(final bool) bool {
+	if r.MinUpdateInterval > 0 {
+		now := time.Now()
+		if !final && !r.lastCall.IsZero() && now.Sub(r.lastCall) < r.MinUpdateInterval {
+			return false
+		}
+		r.lastCall = now
+	}
+	r.Update(r.Progress())
+	r.n = 0
+	return true
+}
+
+// SetReadDeadline sets the read deadline of the underlying R, like
+// a net.Conn, such that reads fail once the deadline is exceeded.
+// If R does not implement a SetReadDeadline(time.Time) error method,
+// SetReadDeadline returns os.ErrNoDeadline.
+func (r *ProgressReader) SetReadDeadline(t time.Time) error {
+	if d, ok := r.R.(interface{ SetReadDeadline(time.Time) error }); ok {
+		return d.SetReadDeadline(t)
+	}
+	return os.ErrNoDeadline
+}
+
 // Progress returns the current progress.
 //
 // It contains the number of bytes read since the
 // last invocation of Update by Read, the total
-// number of bytes read so far and any error that
-// has occurred while reading from R.
+// number of bytes read so far, the average read
+// bandwidth since the first read and any error
+// that has occurred while reading from R.
 func (r *ProgressReader) Progress() Progress {
 	return Progress{
-		N:     r.n,
-		Total: r.total,
-		Err:   r.err,
+		N:          r.n,
+		Total:      r.total,
+		GrandTotal: r.grandTotal,
+		Rate:       r.Rate(),
+		Expected:   r.Expected,
+		Err:        r.err,
+	}
+}
+
+// Rate returns the average read bandwidth since the first read.
+// It is the total number of bytes read so far divided by the time
+// ellapsed since the first read. Hence, it does not jitter like the
+// bandwidth of individual reads. Before the first read, Rate
+// returns 0.
+func (r *ProgressReader) Rate() Bandwidth {
+	if r.start.IsZero() {
+		return 0
 	}
+	return Throughput(r.total, time.Since(r.start))
+}
+
+// PercentString returns the percentage of the expected total
+// number of bytes that have been read so far with one digit
+// after the decimal point, such as "37.5%". The percentage is
+// clamped to [0, 100].
+//
+// If total <= 0, PercentString uses the Expected number of bytes
+// instead. If the expected total is unknown as well, it returns
+// "n/a", consistent with Progress.Percent returning -1.
+//
+// PercentString can be used within the Update function, for
+// example, to print the progress of downloading a file whose
+// size is known in advance.
+func (r *ProgressReader) PercentString(total Size) string {
+	if total <= 0 {
+		total = r.Expected
+	}
+	if total <= 0 {
+		return "n/a"
+	}
+	var buf [8]byte
+	return string(appendPercent(buf[:0], r.total, total))
+}
+
+// Reset resets the ProgressReader to read from rd, for example
+// the next file of a multi-file transfer. It resets the progress
+// of the current operation, including any error, but preserves
+// the GrandTotal. The Update function and update intervals
+// remain unchanged. In particular, the MinUpdateInterval also
+// applies to Update calls across Resets.
+//
+// To reuse a ProgressReader for unrelated streams, use ResetAll.
+func (r *ProgressReader) Reset(rd io.Reader) {
+	r.R = rd
+	r.n, r.total = 0, 0
+	r.start, r.lastUpdate = time.Time{}, time.Time{}
+	r.err = nil
+}
+
+// ResetAll resets the ProgressReader to read from rd like Reset
+// but also resets the GrandTotal and the time of the last Update
+// call. Hence, the MinUpdateInterval does not apply across calls
+// of ResetAll. The Update function and all other exported fields
+// remain unchanged.
+//
+// ResetAll allows reusing a ProgressReader, for example one taken
+// from a sync.Pool, for multiple unrelated streams without
+// allocating a new ProgressReader per stream.
+func (r *ProgressReader) ResetAll(rd io.Reader) {
+	r.Reset(rd)
+	r.grandTotal = 0
+	r.lastCall = time.Time{}
 }
@@ -0,0 +1,174 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// NewRateLimitedReader returns a new RateLimitedReader that reads
+// from r at no more than limit on average. If limit <= 0, reading
+// is not limited.
+func NewRateLimitedReader(r io.Reader, limit Bandwidth) *RateLimitedReader {
+	return &RateLimitedReader{
+		r:       r,
+		limiter: limiter{limit: limit},
+	}
+}
+
+// RateLimitedReader wraps an io.Reader and limits the rate at
+// which data is read. It uses a token bucket that allows short
+// bursts of up to 100ms worth of data and sleeps as needed such
+// that the average read rate does not exceed the limit.
+//
+// The limit can be adjusted by SetLimit while reading.
+type RateLimitedReader struct {
+	r io.Reader
+	limiter
+}
+
+func (r *RateLimitedReader) Read(p []byte) (int, error) {
+	burst, ok := r.burst()
+	if !ok {
+		return r.r.Read(p)
+	}
+	if len(p) > burst {
+		p = p[:burst]
+	}
+
+	n, err := r.r.Read(p)
+	r.wait(n)
+	return n, err
+}
+
+// NewRateLimitedWriter returns a new RateLimitedWriter that writes
+// to w at no more than limit on average. If limit <= 0, writing
+// is not limited.
+func NewRateLimitedWriter(w io.Writer, limit Bandwidth) *RateLimitedWriter {
+	return &RateLimitedWriter{
+		w:       w,
+		limiter: limiter{limit: limit},
+	}
+}
+
+// RateLimitedWriter wraps an io.Writer and limits the rate at
+// which data is written. Like RateLimitedReader, it uses a token
+// bucket that allows short bursts of up to 100ms worth of data.
+// Large writes are split into multiple smaller writes.
+//
+// The limit can be adjusted by SetLimit while writing.
+type RateLimitedWriter struct {
+	w io.Writer
+	limiter
+}
+
+func (w *RateLimitedWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		burst, ok := w.burst()
+		if !ok {
+			n, err := w.w.Write(p)
+			return written + n, err
+		}
+
+		chunk := p
+		if len(chunk) > burst {
+			chunk = chunk[:burst]
+		}
+		n, err := w.w.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+		w.wait(n)
+	}
+	return written, nil
+}
+
+// limiter implements a token bucket that limits the rate
+// of I/O operations to a bandwidth.
+type limiter struct {
+	mu     sync.Mutex
+	limit  Bandwidth
+	tokens float64   // Available bytes; negative if in debt
+	last   time.Time // Last time tokens have been refilled
+}
+
+// Limit returns the current limit. A limit <= 0 means that
+// the I/O operations are not limited.
+func (l *limiter) Limit() Bandwidth {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// SetLimit sets the limit to the given bandwidth. It can be
+// called concurrently to I/O operations. A limit <= 0 means
+// that the I/O operations are not limited.
+func (l *limiter) SetLimit(limit Bandwidth) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+}
+
+// burst returns the max. number of bytes that should be
+// processed at once. It returns false if there is no limit.
+func (l *limiter) burst() (int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit <= 0 {
+		return 0, false
+	}
+	return int(l.burstSize()), true
+}
+
+// burstSize returns the size of the token bucket in bytes,
+// i.e. the amount of data transferred within 100ms, but at
+// least one byte. The caller must hold l.mu.
+func (l *limiter) burstSize() float64 {
+	if burst := l.rate() / 10; burst > 1 {
+		return float64(int64(burst))
+	}
+	return 1
+}
+
+// rate returns the limit in bytes per second. The caller
+// must hold l.mu.
+func (l *limiter) rate() float64 { return float64(l.limit) / float64(BytePerSecond) }
+
+// wait takes n bytes from the token bucket and sleeps until
+// the bucket is no longer in debt.
+func (l *limiter) wait(n int) {
+	l.mu.Lock()
+	if l.limit <= 0 {
+		l.mu.Unlock()
+		return
+	}
+
+	now, burst := time.Now(), l.burstSize()
+	if l.last.IsZero() {
+		l.tokens = burst
+	} else {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate()
+	}
+	if l.tokens > burst {
+		l.tokens = burst
+	}
+	l.last = now
+	l.tokens -= float64(n)
+
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate() * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedReader(t *testing.T) {
+	for i, test := range rateLimitTests {
+		data := make([]byte, test.Size)
+		r := NewRateLimitedReader(bytes.NewReader(data), test.Limit)
+
+		start := time.Now()
+		n, err := io.Copy(io.Discard, r)
+		if err != nil {
+			t.Fatalf("Test %d: failed to read: %v", i, err)
+		}
+		if n != int64(test.Size) {
+			t.Fatalf("Test %d: got %d bytes - want %d", i, n, test.Size)
+		}
+		if elapsed := time.Since(start); elapsed < test.Min {
+			t.Fatalf("Test %d: reading took %v - want at least %v", i, elapsed, test.Min)
+		}
+	}
+}
+
+func TestRateLimitedWriter(t *testing.T) {
+	for i, test := range rateLimitTests {
+		var buf bytes.Buffer
+		w := NewRateLimitedWriter(&buf, test.Limit)
+
+		start := time.Now()
+		n, err := w.Write(make([]byte, test.Size))
+		if err != nil {
+			t.Fatalf("Test %d: failed to write: %v", i, err)
+		}
+		if n != int(test.Size) || buf.Len() != int(test.Size) {
+			t.Fatalf("Test %d: got %d bytes - want %d", i, n, test.Size)
+		}
+		if elapsed := time.Since(start); elapsed < test.Min {
+			t.Fatalf("Test %d: writing took %v - want at least %v", i, elapsed, test.Min)
+		}
+	}
+}
+
+var rateLimitTests = []struct {
+	Limit Bandwidth
+	Size  Size
+	Min   time.Duration
+}{
+	{Limit: 0, Size: 1 * MB, Min: 0},                                          // 0
+	{Limit: -MBytePerSecond, Size: 1 * MB, Min: 0},                            // 1
+	{Limit: MBytePerSecond, Size: 50 * KB, Min: 0},                            // 2
+	{Limit: MBytePerSecond, Size: 200 * KB, Min: 90 * time.Millisecond},       // 3
+	{Limit: 10 * MiBytePerSecond, Size: 3 * MiB, Min: 190 * time.Millisecond}, // 4
+}
+
+func TestRateLimitedReader_SetLimit(t *testing.T) {
+	r := NewRateLimitedReader(bytes.NewReader(make([]byte, 1*MB)), 80*BitPerSecond)
+	if limit := r.Limit(); limit != 80*BitPerSecond {
+		t.Fatalf("got limit %v - want %v", limit, 80*BitPerSecond)
+	}
+
+	buf := make([]byte, 64*KB)
+	if n, err := r.Read(buf); err != nil || n != 1 {
+		t.Fatalf("got %d bytes and error %v - want 1 byte", n, err)
+	}
+
+	r.SetLimit(0)
+	start := time.Now()
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("reading without limit took %v", elapsed)
+	}
+}
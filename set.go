@@ -0,0 +1,35 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import "sort"
+
+// SizeSet is a set of distinct sizes. The zero value is
+// an empty set that must be created with make before adding
+// any size:
+//
+//	set := make(mem.SizeSet)
+//	set.Add(4 * mem.KiB)
+type SizeSet map[Size]struct{}
+
+// Add adds s to the set.
+func (set SizeSet) Add(s Size) { set[s] = struct{}{} }
+
+// Contains reports whether s is in the set.
+func (set SizeSet) Contains(s Size) bool {
+	_, ok := set[s]
+	return ok
+}
+
+// Sorted returns the sizes of the set sorted in
+// ascending order.
+func (set SizeSet) Sorted() []Size {
+	sizes := make([]Size, 0, len(set))
+	for s := range set {
+		sizes = append(sizes, s)
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] < sizes[j] })
+	return sizes
+}
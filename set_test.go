@@ -0,0 +1,52 @@
+// Copyright (c) 2022 Andreas Auernhammer. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package mem
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSizeSet(t *testing.T) {
+	for i, test := range sizeSetTests {
+		set := make(SizeSet)
+		for _, s := range test.Add {
+			set.Add(s)
+		}
+		for _, s := range test.Add {
+			if !set.Contains(s) {
+				t.Fatalf("Test %d: set does not contain %v", i, s)
+			}
+		}
+
+		sorted := set.Sorted()
+		if len(sorted) != len(test.Sorted) {
+			t.Fatalf("Test %d: got %d sizes - want %d", i, len(sorted), len(test.Sorted))
+		}
+		for j := range sorted {
+			if sorted[j] != test.Sorted[j] {
+				t.Fatalf("Test %d: element %d: got %v - want %v", i, j, sorted[j], test.Sorted[j])
+			}
+		}
+	}
+
+	var set SizeSet
+	if set.Contains(0) {
+		t.Fatal("Empty set contains 0")
+	}
+	if sorted := set.Sorted(); len(sorted) != 0 {
+		t.Fatalf("Empty set is not empty: %v", sorted)
+	}
+}
+
+var sizeSetTests = []struct {
+	Add    []Size
+	Sorted []Size
+}{
+	{Add: nil, Sorted: nil},                                                                   // 0
+	{Add: []Size{KB, KB, KB}, Sorted: []Size{KB}},                                             // 1
+	{Add: []Size{MB, KiB, 0, -KB, MB, KiB}, Sorted: []Size{-KB, 0, KiB, MB}},                  // 2
+	{Add: []Size{math.MaxInt64, math.MinInt64}, Sorted: []Size{math.MinInt64, math.MaxInt64}}, // 3
+}
@@ -4,7 +4,18 @@
 
 package mem
 
-import "math"
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"os"
+	"strconv"
+)
 
 // Common sizes for measuring memory and disk capacity.
 //
@@ -25,16 +36,34 @@ const (
 	GB      = 1000 * MB
 	TB      = 1000 * GB
 	PB      = 1000 * TB
+	EB      = 1000 * PB
 
 	KiB Size = 1024 * Byte
 	MiB      = 1024 * KiB
 	GiB      = 1024 * MiB
 	TiB      = 1024 * GiB
 	PiB      = 1024 * TiB
+	EiB      = 1024 * PiB
 )
 
+// Common block sizes of storage devices and memory.
+const (
+	// SectorSize is the size of a classic disk sector.
+	SectorSize = 512 * Byte
+
+	// PageSize is the most common size of a memory page
+	// and the block size of many file systems. The page
+	// size of the running system is returned by OSPageSize.
+	PageSize = 4 * KiB
+)
+
+// OSPageSize returns the memory page size of the
+// underlying operating system. It is equivalent to
+// os.Getpagesize.
+func OSPageSize() Size { return Size(os.Getpagesize()) }
+
 // Size represents an amount of data as int64 number of bytes.
-// The largest representable size is approximately 8192 PiB.
+// The largest representable size is approximately 8 EiB.
 type Size int64
 
 // Bits returns s as number of bits. As special cases, if s would
@@ -51,6 +80,69 @@ func (s Size) Bits() BitSize {
 	}
 }
 
+// CmpBits compares s to the bit size b and returns:
+//
+//	-1 if s is less than b
+//	 0 if s is equal to b
+//	+1 if s is greater than b
+//
+// The comparison is exact, even if s cannot be represented as
+// BitSize. For example, math.MaxInt64 bytes are greater than
+// math.MaxInt64 bits, although s.Bits() saturates at the max.
+// BitSize.
+func (s Size) CmpBits(b BitSize) int {
+	bytes := b.BytesFloor()
+	switch {
+	case s < bytes:
+		return -1
+	case s > bytes:
+		return 1
+	case b-bytes.Bits() > 0: // b is not a multiple of 8 bits
+		return -1
+	default:
+		return 0
+	}
+}
+
+// Compare compares s to t and returns:
+//
+//	-1 if s is less than t
+//	 0 if s is equal to t
+//	+1 if s is greater than t
+//
+// It can be used to sort sizes, for example with sort.Slice:
+//
+//	sort.Slice(sizes, func(i, j int) bool { return sizes[i].Compare(sizes[j]) < 0 })
+func (s Size) Compare(t Size) int {
+	switch {
+	case s < t:
+		return -1
+	case s > t:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsZero reports whether s is zero.
+func (s Size) IsZero() bool { return s == 0 }
+
+// IsNegative reports whether s is less than zero.
+func (s Size) IsNegative() bool { return s < 0 }
+
+// Sign returns -1 if s is negative, 0 if s is zero
+// and +1 if s is positive.
+func (s Size) Sign() int {
+	switch {
+	case s < 0:
+		return -1
+	case s > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
 // Kilobytes returns the size as floating point number of kilobytes (KB).
 func (s Size) Kilobytes() float64 {
 	k := s / KB
@@ -86,6 +178,13 @@ func (s Size) Petabytes() float64 {
 	return float64(p) + float64(r)/1e15
 }
 
+// Exabytes returns the size as floating point number of exabytes (EB).
+func (s Size) Exabytes() float64 {
+	e := s / EB
+	r := s % EB
+	return float64(e) + float64(r)/1e18
+}
+
 // Kibibytes returns the size as floating point number of kibibytes (KiB).
 func (s Size) Kibibytes() float64 {
 	k := s / KiB
@@ -121,6 +220,43 @@ func (s Size) Pebibytes() float64 {
 	return float64(p) + float64(r)/(1<<50)
 }
 
+// Exbibytes returns the size as floating point number of exbibytes (EiB).
+func (s Size) Exbibytes() float64 {
+	e := s / EiB
+	r := s % EiB
+	return float64(e) + float64(r)/(1<<60)
+}
+
+// Kilobits returns the size as floating point number of kilobits (Kbit).
+func (s Size) Kilobits() float64 {
+	// One kilobit is 125 bytes. Dividing by 125 instead of
+	// multiplying by 8 avoids overflows for large sizes.
+	k := s / 125
+	r := s % 125
+	return float64(k) + float64(r)/125
+}
+
+// Megabits returns the size as floating point number of megabits (Mbit).
+func (s Size) Megabits() float64 {
+	m := s / (125 * KB)
+	r := s % (125 * KB)
+	return float64(m) + float64(r)/125e3
+}
+
+// Gigabits returns the size as floating point number of gigabits (Gbit).
+func (s Size) Gigabits() float64 {
+	g := s / (125 * MB)
+	r := s % (125 * MB)
+	return float64(g) + float64(r)/125e6
+}
+
+// Terabits returns the size as floating point number of terabits (Tbit).
+func (s Size) Terabits() float64 {
+	t := s / (125 * GB)
+	r := s % (125 * GB)
+	return float64(t) + float64(r)/125e9
+}
+
 // Abs returns the absolute value of s. As a special case, math.MinInt64 is
 // converted to math.MaxInt64.
 func (s Size) Abs() Size {
@@ -142,6 +278,645 @@ func (s Size) Round(m Size) Size {
 	return Size(round(int64(s), int64(m)))
 }
 
+// IsAligned reports whether s is a multiple of boundary, for
+// example of the block size of a storage device. IsAligned
+// returns false if boundary <= 0.
+func (s Size) IsAligned(boundary Size) bool {
+	return boundary > 0 && s%boundary == 0
+}
+
+// ChunkCount returns the number of chunks of the given chunk size
+// that are needed to cover s. For example, 10MB are covered by 3
+// chunks of 4MB each. ChunkCount returns 0 if s <= 0 or chunk <= 0.
+// It saturates at the max. int value.
+func (s Size) ChunkCount(chunk Size) int {
+	if s <= 0 || chunk <= 0 {
+		return 0
+	}
+	n := s / chunk
+	if s%chunk != 0 {
+		n++
+	}
+	if int64(n) > math.MaxInt {
+		return math.MaxInt
+	}
+	return int(n)
+}
+
+// SignificantFigures returns the result of rounding s to n significant
+// decimal digits. For example, 1234567890 bytes rounded to 3 significant
+// figures is 1230000000 bytes, which formats as 1.23GB. The rounding
+// behavior for halfway values is to round away from zero. If the result
+// exceeds the max. resp. min. representable Size, SignificantFigures
+// returns the max. resp. min. size. If n <= 0, SignificantFigures
+// returns s unchanged.
+func (s Size) SignificantFigures(n int) Size {
+	if n <= 0 {
+		return s
+	}
+
+	m := int64(1)
+	for v := abs(int64(s)); v >= 10; v /= 10 {
+		if n--; n <= 0 {
+			m *= 10
+		}
+	}
+	return Size(round(int64(s), m))
+}
+
+// NearestUnit returns the decimal unit, like GB, that FormatSize
+// uses to format s with the 'D' format, together with its label,
+// like "GB". It is the largest unit not greater than the absolute
+// value of s. The zero size has the unit Byte labeled "B".
+func (s Size) NearestUnit() (unit Size, label string) {
+	switch {
+	case s >= EB || s <= -EB:
+		return EB, "EB"
+	case s >= PB || s <= -PB:
+		return PB, "PB"
+	case s >= TB || s <= -TB:
+		return TB, "TB"
+	case s >= GB || s <= -GB:
+		return GB, "GB"
+	case s >= MB || s <= -MB:
+		return MB, "MB"
+	case s >= KB || s <= -KB:
+		return KB, "KB"
+	default:
+		return Byte, "B"
+	}
+}
+
+// AsBinary reinterprets s, given in decimal units, as the same
+// number of binary units. For example, 1GB becomes 1GiB and 1.5MB
+// becomes 1.5MiB. The unit is the largest decimal unit not greater
+// than the absolute value of s, like for FormatSize. Sizes smaller
+// than 1KB remain unchanged.
+//
+// AsBinary changes the value of s, not just its representation.
+// It is intended for reconciling sizes that have been specified
+// in decimal units but are meant as binary units, or vice versa.
+// The result is rounded to the nearest byte and saturates at the
+// max. resp. min. representable Size.
+func (s Size) AsBinary() Size {
+	switch {
+	case s >= EB || s <= -EB:
+		return Size(rescale(int64(s), int64(EB), int64(EiB)))
+	case s >= PB || s <= -PB:
+		return Size(rescale(int64(s), int64(PB), int64(PiB)))
+	case s >= TB || s <= -TB:
+		return Size(rescale(int64(s), int64(TB), int64(TiB)))
+	case s >= GB || s <= -GB:
+		return Size(rescale(int64(s), int64(GB), int64(GiB)))
+	case s >= MB || s <= -MB:
+		return Size(rescale(int64(s), int64(MB), int64(MiB)))
+	case s >= KB || s <= -KB:
+		return Size(rescale(int64(s), int64(KB), int64(KiB)))
+	default:
+		return s
+	}
+}
+
+// AsDecimal reinterprets s, given in binary units, as the same
+// number of decimal units. For example, 1GiB becomes 1GB and 1.5MiB
+// becomes 1.5MB. The unit is the largest binary unit not greater
+// than the absolute value of s, like for FormatSize. Sizes smaller
+// than 1KiB remain unchanged.
+//
+// Like AsBinary, AsDecimal changes the value of s, not just its
+// representation. The result is rounded to the nearest byte.
+func (s Size) AsDecimal() Size {
+	switch {
+	case s >= EiB || s <= -EiB:
+		return Size(rescale(int64(s), int64(EiB), int64(EB)))
+	case s >= PiB || s <= -PiB:
+		return Size(rescale(int64(s), int64(PiB), int64(PB)))
+	case s >= TiB || s <= -TiB:
+		return Size(rescale(int64(s), int64(TiB), int64(TB)))
+	case s >= GiB || s <= -GiB:
+		return Size(rescale(int64(s), int64(GiB), int64(GB)))
+	case s >= MiB || s <= -MiB:
+		return Size(rescale(int64(s), int64(MiB), int64(MB)))
+	case s >= KiB || s <= -KiB:
+		return Size(rescale(int64(s), int64(KiB), int64(KB)))
+	default:
+		return s
+	}
+}
+
+// Log2 returns the binary logarithm of s rounded down to the next
+// integer. For example, the Log2 of 1KiB and of 1.5KiB is 10. Log2
+// returns -1 if s <= 0.
+func (s Size) Log2() int {
+	if s <= 0 {
+		return -1
+	}
+	return bits.Len64(uint64(s)) - 1
+}
+
+// Bucket returns the smallest power of two that is greater than or
+// equal to s. For example, the Bucket of 1KiB is 1KiB and the Bucket
+// of 1.5KiB is 2KiB. Bucket returns 0 if s <= 0.
+//
+// As a special case, Bucket returns math.MaxInt64 if s is greater
+// than 1<<62, the largest power of two that can be represented as
+// Size.
+func (s Size) Bucket() Size {
+	switch {
+	case s <= 0:
+		return 0
+	case s > 1<<62:
+		return math.MaxInt64
+	default:
+		return 1 << bits.Len64(uint64(s-1))
+	}
+}
+
+// Min returns the smaller of s and other. For example,
+// s.Min(16*mem.MB) limits s to at most 16MB.
+func (s Size) Min(other Size) Size {
+	if s < other {
+		return s
+	}
+	return other
+}
+
+// Max returns the larger of s and other. For example,
+// s.Max(4*mem.KiB) ensures that s is at least 4KiB.
+func (s Size) Max(other Size) Size {
+	if s > other {
+		return s
+	}
+	return other
+}
+
+// Clamp returns s limited to the range [min, max]. It returns min
+// if s is smaller than min and max if s is greater than max. For
+// example, to enforce configuration limits:
+//
+//	bufSize = bufSize.Clamp(mem.KB, 16*mem.MB)
+//
+// If min is greater than max, the range is empty and Clamp returns
+// s unchanged.
+func (s Size) Clamp(min, max Size) Size {
+	switch {
+	case min > max:
+		return s
+	case s < min:
+		return min
+	case s > max:
+		return max
+	default:
+		return s
+	}
+}
+
+// Delta returns the absolute difference between s and from and
+// whether s is greater than from. For example, a size that grew
+// from 1MB to 3MB has a delta of 2MB:
+//
+//	delta, grew := (3 * mem.MB).Delta(1 * mem.MB)
+//	fmt.Print(delta, grew) // prints 2MB true
+//
+// As a special case, if the difference exceeds the max. representable
+// Size, Delta returns math.MaxInt64.
+func (s Size) Delta(from Size) (delta Size, grew bool) {
+	return Size(absDiff(int64(s), int64(from))), s > from
+}
+
+// Times returns s multiplied by the unitless count n. For example,
+// the total size of n records of recordSize bytes each is:
+//
+//	total := recordSize.Times(n)
+//
+// If the product exceeds the max. resp. min. representable Size,
+// Times returns math.MaxInt64 resp. math.MinInt64 instead of
+// overflowing.
+func (s Size) Times(n int64) Size {
+	return Size(mul(int64(s), n))
+}
+
+// Add returns s + t and reports whether the sum overflowed. If
+// it overflows, Add returns the max. resp. min. representable
+// Size instead of wrapping around. It can be used to accumulate
+// untrusted sizes, like user-supplied quotas:
+//
+//	total, overflow := total.Add(quota)
+func (s Size) Add(t Size) (Size, bool) {
+	sum, overflow := addOverflow(int64(s), int64(t))
+	return Size(sum), overflow
+}
+
+// Sub returns s - t and reports whether the difference
+// overflowed. If it overflows, Sub returns the max. resp.
+// min. representable Size instead of wrapping around.
+func (s Size) Sub(t Size) (Size, bool) {
+	diff, overflow := subOverflow(int64(s), int64(t))
+	return Size(diff), overflow
+}
+
+// Mul returns s multiplied by the unitless count n and reports
+// whether the product overflowed. If it overflows, Mul returns
+// the max. resp. min. representable Size, like Times.
+func (s Size) Mul(n int64) (Size, bool) {
+	p, overflow := mulOverflow(int64(s), n)
+	return Size(p), overflow
+}
+
+// Saved returns the amount of data saved by storing s bytes as
+// stored bytes, for example due to compression or deduplication.
+// It is equal to s - stored but saturates at the max. resp. min.
+// representable Size instead of overflowing.
+func (s Size) Saved(stored Size) Size {
+	return Size(sub(int64(s), int64(stored)))
+}
+
+// Efficiency returns the ratio stored/s of storing s bytes as stored
+// bytes, for example due to compression or deduplication. A ratio
+// less than 1 indicates that less than s bytes have been stored.
+// Efficiency returns 0 if s is zero.
+func (s Size) Efficiency(stored Size) float64 {
+	if s == 0 {
+		return 0
+	}
+	return float64(stored) / float64(s)
+}
+
+// Min returns the smaller of a and b. It is equivalent to a.Min(b).
+func Min(a, b Size) Size { return a.Min(b) }
+
+// Max returns the larger of a and b. It is equivalent to a.Max(b).
+func Max(a, b Size) Size { return a.Max(b) }
+
+// Coalesce returns the first non-zero size, or 0 if all sizes
+// are zero. It is useful when merging layered configurations:
+//
+//	bufSize := mem.Coalesce(flagBufSize, envBufSize, 64*mem.KiB)
+func Coalesce(sizes ...Size) Size {
+	for _, s := range sizes {
+		if s != 0 {
+			return s
+		}
+	}
+	return 0
+}
+
+// GeometricMeanSize returns the geometric mean of the given sizes
+// rounded to the nearest byte. It is computed in float space and
+// hence not exact for large sizes.
+//
+// The geometric mean is only defined for positive values. If any
+// size is zero, GeometricMeanSize returns 0. If any size is negative
+// or no size is given, GeometricMeanSize returns 0, too.
+func GeometricMeanSize(sizes ...Size) Size {
+	if len(sizes) == 0 {
+		return 0
+	}
+
+	max := sizes[0]
+	for _, s := range sizes {
+		if s <= 0 {
+			return 0
+		}
+		if s > max {
+			max = s
+		}
+	}
+
+	// We sum the logarithms relative to the largest size to
+	// reduce the floating point error for large sizes.
+	var sum float64
+	for _, s := range sizes {
+		sum += math.Log(float64(s) / float64(max))
+	}
+	mean := math.Round(float64(max) * math.Exp(sum/float64(len(sizes))))
+	if mean >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return Size(mean)
+}
+
+// FreeSpace returns the remaining space capacity - used of a storage
+// device or quota. FreeSpace never returns a negative size. If used
+// exceeds capacity, FreeSpace returns 0.
+func FreeSpace(capacity, used Size) Size {
+	return Size(sub(int64(capacity), int64(used))).Max(0)
+}
+
+// UsedPercent returns the percentage of capacity that is used,
+// clamped to [0, 100]. If capacity <= 0, UsedPercent returns 0.
+func UsedPercent(capacity, used Size) float64 {
+	if capacity <= 0 {
+		return 0
+	}
+	p := float64(used) / float64(capacity) * 100
+	switch {
+	case p < 0:
+		return 0
+	case p > 100:
+		return 100
+	default:
+		return p
+	}
+}
+
+// Ratio returns the ratio s/other, for example the compression
+// ratio of s bytes of data compressed to other bytes. Ratio
+// returns 0 if other is zero.
+func (s Size) Ratio(other Size) float64 {
+	if other == 0 {
+		return 0
+	}
+	return float64(s) / float64(other)
+}
+
 // String returns a string representing the size in the form "1.25MB".
-// The zero size formats as 0B.
+// The zero size formats as 0B. String always uses decimal units. Use
+// BinaryString for binary units.
 func (s Size) String() string { return FormatSize(s, 'D', -1) }
+
+// Format implements the fmt.Formatter interface. The verbs %v, %s
+// and %d format s using decimal units, like "1.25MB", and the verb
+// %b formats s using binary units, like "1.5MiB". The precision
+// controls the number of digits after the decimal point, like for
+// FormatSize. Without a precision, s is formatted exactly. For
+// example:
+//
+//	fmt.Printf("%.2d", 1500*mem.KiB)   // prints 1.54MB
+//	fmt.Printf("%8.1b|", 1500*mem.KiB) // prints "  1.5MiB|"
+//	fmt.Printf("%-6v|", 512*mem.Byte)  // prints "512B  |"
+//
+// The width pads the result with spaces, on the left by default or
+// on the right with the '-' flag. The '+' flag prints a plus sign for
+// positive sizes, and the ' ' flag a leading space instead.
+//
+// Any other verb, like %q or %x, formats the String representation
+// of s with the same flags, width and precision.
+func (s Size) Format(f fmt.State, verb rune) {
+	var format byte
+	switch verb {
+	case 'v', 's', 'd':
+		format = 'D'
+	case 'b':
+		format = 'B'
+	default:
+		fmt.Fprintf(f, formatString(f, verb), s.String())
+		return
+	}
+	prec, ok := f.Precision()
+	if !ok {
+		prec = -1
+	}
+
+	var buf [32]byte
+	writeFormatted(f, AppendSize(buf[:0], s, format, prec), s >= 0)
+}
+
+// BinaryString returns a string representing the size using binary
+// units in the form "1.25MiB". The zero size formats as 0B.
+func (s Size) BinaryString() string { return FormatSize(s, 'B', -1) }
+
+// DualString returns a string representing the size in both,
+// decimal and binary units, in the form "1.5GB (1.4GiB)". Each
+// representation has at most two digits after the decimal point.
+func (s Size) DualString() string {
+	opts := FormatOptions{Compact: true}
+	return FormatSizeWith(s, 'D', 2, opts) + " (" + FormatSizeWith(s, 'B', 2, opts) + ")"
+}
+
+// AppendString appends the string form of s, as returned by String,
+// to dst and returns the extended buffer.
+func (s Size) AppendString(dst []byte) []byte { return AppendSize(dst, s, 'D', -1) }
+
+// Query returns the size as plain decimal number of bytes, such as
+// "1610612736", that can be used in a URL without any escaping. It
+// can be parsed by ParseSizeQuery.
+func (s Size) Query() string { return strconv.FormatInt(int64(s), 10) }
+
+// Words returns the size spelled out in decimal units, largest
+// first, such as "1 petabyte, 234 terabytes, 56 gigabytes". Zero
+// components are omitted. The zero size is "0 bytes". Negative
+// sizes are prefixed with a '-' sign, like "-1 kilobyte, 500 bytes".
+func (s Size) Words() string {
+	if s == 0 {
+		return "0 bytes"
+	}
+
+	units := [...]struct {
+		Size Size
+		Name string
+	}{
+		{EB, "exabyte"},
+		{PB, "petabyte"},
+		{TB, "terabyte"},
+		{GB, "gigabyte"},
+		{MB, "megabyte"},
+		{KB, "kilobyte"},
+		{Byte, "byte"},
+	}
+
+	var buf []byte
+	v := uint64(s)
+	if s < 0 {
+		buf = append(buf, '-')
+		v = -v // Two's complement - works for math.MinInt64, too
+	}
+	var sep string
+	for _, u := range units {
+		n := v / uint64(u.Size)
+		if n == 0 {
+			continue
+		}
+		v %= uint64(u.Size)
+
+		buf = append(buf, sep...)
+		sep = ", "
+		buf = strconv.AppendUint(buf, n, 10)
+		buf = append(buf, ' ')
+		buf = append(buf, u.Name...)
+		if n != 1 {
+			buf = append(buf, 's')
+		}
+	}
+	return string(buf)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The size is encoded in the form "1.25MB", like String.
+func (s Size) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The text is parsed using ParseSize. A single pair of surrounding
+// double quotes, like in "\"5MiB\"", is removed before parsing.
+func (s *Size) UnmarshalText(text []byte) error {
+	v, err := ParseSize(string(unquote(text)))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// unquote removes a single pair of surrounding double quotes from
+// text, if present. Config formats often quote values, like in
+// "\"5MiB\"", and pass them to UnmarshalText as they are.
+func unquote(text []byte) []byte {
+	if n := len(text); n >= 2 && text[0] == '"' && text[n-1] == '"' {
+		return text[1 : n-1]
+	}
+	return text
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The size is encoded as 8 byte big-endian two's complement integer
+// number of bytes. This encoding is stable and will not change. It
+// is used by encoding/gob, for example.
+func (s Size) MarshalBinary() ([]byte, error) {
+	return binary.BigEndian.AppendUint64(make([]byte, 0, 8), uint64(s)), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// It decodes the 8 byte encoding produced by MarshalBinary.
+func (s *Size) UnmarshalBinary(data []byte) error {
+	v, err := unmarshalBinary(data, "size")
+	if err != nil {
+		return err
+	}
+	*s = Size(v)
+	return nil
+}
+
+// unmarshalBinary decodes the 8 byte big-endian encoding of
+// an int64 value of the given type, like "size" or "bit size".
+func unmarshalBinary(data []byte, typ string) (int64, error) {
+	if len(data) != 8 {
+		return 0, errors.New("mem: invalid binary " + typ + " encoding of length " + strconv.Itoa(len(data)))
+	}
+	return int64(binary.BigEndian.Uint64(data)), nil
+}
+
+// Set parses s using ParseSize and sets the size to the result.
+// Together with String, it implements the flag.Value interface
+// such that a *Size can be used as command line flag:
+//
+//	var maxUpload = 20 * mem.MiB
+//	flag.Var(&maxUpload, "max-upload", "maximum upload size")
+//
+// String produces a representation that Set accepts.
+func (s *Size) Set(v string) error {
+	size, err := ParseSize(v)
+	if err != nil {
+		return err
+	}
+	*s = size
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The size is encoded as JSON string in the form "1.25MB",
+// like String.
+func (s Size) MarshalJSON() ([]byte, error) {
+	var buf [26]byte
+	b := append(buf[:0], '"')
+	b = AppendSize(b, s, 'D', -1)
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// It accepts both, JSON strings and JSON numbers. A string,
+// like "5MiB", is parsed using ParseSize. A number, like
+// 5242880, is interpreted as number of bytes. Numbers with
+// a non-zero fraction, like 5.5, are rejected instead of
+// being truncated. The JSON null value is ignored.
+//
+// Hence, the JSON values "5" and 5 are not equivalent. The
+// former is rejected since it lacks a unit while the latter
+// is a size of 5 bytes.
+func (s *Size) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+		v, err := ParseSize(str)
+		if err != nil {
+			return err
+		}
+		*s = v
+		return nil
+	}
+
+	var num json.Number
+	if err := json.Unmarshal(data, &num); err != nil {
+		return err
+	}
+	if v, err := strconv.ParseInt(num.String(), 10, 64); err == nil {
+		*s = Size(v)
+		return nil
+	}
+	// Numbers like 5e6 or 1.0 are valid as long as they don't
+	// have a fraction and are within the range of a Size.
+	f, err := num.Float64()
+	if err != nil || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return errors.New("mem: invalid size '" + num.String() + "'")
+	}
+	*s = Size(f)
+	return nil
+}
+
+// Value implements the driver.Valuer interface. The size is stored
+// as int64 number of bytes, like 1610612736, and not as size string,
+// like "1.5GiB". Hence, a Size can be stored in an integer column,
+// such as a BIGINT.
+func (s Size) Value() (driver.Value, error) { return int64(s), nil }
+
+// Scan implements the sql.Scanner interface. It accepts integers,
+// which are interpreted as number of bytes, and strings resp. byte
+// slices. A string can be a plain number of bytes, like "1610612736",
+// or a size string, like "1.5GiB", and is parsed using ParseSizeQuery.
+// Scan rejects NULL values and any other type.
+func (s *Size) Scan(src any) error {
+	switch v := src.(type) {
+	case int64:
+		*s = Size(v)
+		return nil
+	case []byte:
+		size, err := ParseSizeQuery(string(v))
+		if err != nil {
+			return err
+		}
+		*s = size
+		return nil
+	case string:
+		size, err := ParseSizeQuery(v)
+		if err != nil {
+			return err
+		}
+		*s = size
+		return nil
+	default:
+		return fmt.Errorf("mem: cannot scan %T into Size", src)
+	}
+}
+
+// DecimalSize is a Size that is displayed using decimal units,
+// like "1.5GB". It can be used, for example as struct field type,
+// to control how individual sizes are displayed.
+type DecimalSize Size
+
+// String returns a string representing the size in the form "1.25MB".
+// It is equivalent to Size.String.
+func (s DecimalSize) String() string { return Size(s).String() }
+
+// BinarySize is a Size that is displayed using binary units,
+// like "1.5GiB". It can be used, for example as struct field type,
+// to control how individual sizes are displayed.
+type BinarySize Size
+
+// String returns a string representing the size in the form "1.25MiB".
+// It is equivalent to Size.BinaryString.
+func (s BinarySize) String() string { return Size(s).BinaryString() }
@@ -5,7 +5,12 @@
 package mem
 
 import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
 	"math"
+	"strings"
 	"testing"
 )
 
@@ -28,7 +33,80 @@ var sizeStringTests = []struct {
 	{Size: MiB, String: "1.048576MB"},                        // 4
 	{Size: 5*TB + 640*GB + 509*MB, String: "5.640509TB"},     // 5
 	{Size: -1*MB - 825*KB, String: "-1.825MB"},               // 6
-	{Size: 1000*PB + Byte, String: "1000.000000000000001PB"}, // 7
+	{Size: 1000*PB + Byte, String: "1.000000000000000001EB"}, // 7
+	{Size: 999*PB + Byte, String: "999.000000000000001PB"},   // 8
+}
+
+func TestSize_BinaryString(t *testing.T) {
+	for i, test := range sizeBinaryStringTests {
+		if s := test.Size.BinaryString(); s != test.Binary {
+			t.Fatalf("Test %d: got %s - want %s", i, s, test.Binary)
+		}
+		if s := BinarySize(test.Size).String(); s != test.Binary {
+			t.Fatalf("Test %d: got %s - want %s", i, s, test.Binary)
+		}
+		if s := DecimalSize(test.Size).String(); s != test.Decimal {
+			t.Fatalf("Test %d: got %s - want %s", i, s, test.Decimal)
+		}
+	}
+
+	// DecimalSize and BinarySize are displayed
+	// according to their type by the fmt package.
+	v := struct {
+		A DecimalSize
+		B BinarySize
+	}{A: DecimalSize(1536), B: BinarySize(1536)}
+	if s := fmt.Sprint(v); s != "{1.536KB 1.5KiB}" {
+		t.Fatalf("got %s - want %s", s, "{1.536KB 1.5KiB}")
+	}
+}
+
+var sizeBinaryStringTests = []struct {
+	Size    Size
+	Binary  string
+	Decimal string
+}{
+	{Size: 0, Binary: "0B", Decimal: "0B"},                // 0
+	{Size: 1536, Binary: "1.5KiB", Decimal: "1.536KB"},    // 1
+	{Size: GiB, Binary: "1GiB", Decimal: "1.073741824GB"}, // 2
+	{Size: -MB, Binary: "-976.5625KiB", Decimal: "-1MB"},  // 3
+	{Size: 1000, Binary: "1000B", Decimal: "1KB"},         // 4
+}
+
+func TestSize_DualString(t *testing.T) {
+	for i, test := range sizeDualStringTests {
+		if s := test.Size.DualString(); s != test.String {
+			t.Fatalf("Test %d: got %s - want %s", i, s, test.String)
+		}
+	}
+}
+
+var sizeDualStringTests = []struct {
+	Size   Size
+	String string
+}{
+	{Size: 0, String: "0B (0B)"},                   // 0
+	{Size: 512, String: "512B (512B)"},             // 1
+	{Size: 1500 * MB, String: "1.5GB (1.4GiB)"},    // 2
+	{Size: GiB, String: "1.07GB (1GiB)"},           // 3
+	{Size: -2 * MB, String: "-2MB (-1.91MiB)"},     // 4
+	{Size: 1234567890, String: "1.23GB (1.15GiB)"}, // 5
+}
+
+func TestSize_AppendString(t *testing.T) {
+	var buf []byte
+	for i, test := range sizeStringTests {
+		buf = test.Size.AppendString(buf[:0])
+		if s := string(buf); s != test.String {
+			t.Fatalf("Test %d: got %s - want %s", i, s, test.String)
+		}
+	}
+
+	buf = []byte("size=")
+	buf = MB.AppendString(buf)
+	if s := string(buf); s != "size=1MB" {
+		t.Fatalf("got %s - want %s", s, "size=1MB")
+	}
 }
 
 func TestSize_Bits(t *testing.T) {
@@ -52,6 +130,37 @@ var sizeBitsTests = []struct {
 	{Size: math.MaxInt64 / 8, Bits: math.MaxInt64 - 7}, // 6
 }
 
+func TestSize_CmpBits(t *testing.T) {
+	for i, test := range sizeCmpBitsTests {
+		if c := test.Size.CmpBits(test.Bits); c != test.Cmp {
+			t.Fatalf("Test %d: got %d - want %d", i, c, test.Cmp)
+		}
+	}
+}
+
+var sizeCmpBitsTests = []struct {
+	Size Size
+	Bits BitSize
+	Cmp  int
+}{
+	{Size: 0, Bits: 0, Cmp: 0},                                // 0
+	{Size: 1, Bits: 8, Cmp: 0},                                // 1
+	{Size: 1, Bits: 7, Cmp: 1},                                // 2
+	{Size: 1, Bits: 9, Cmp: -1},                               // 3
+	{Size: 0, Bits: 1, Cmp: -1},                               // 4
+	{Size: 0, Bits: -1, Cmp: 1},                               // 5
+	{Size: -1, Bits: -8, Cmp: 0},                              // 6
+	{Size: -1, Bits: -7, Cmp: -1},                             // 7
+	{Size: -1, Bits: -9, Cmp: 1},                              // 8
+	{Size: 125 * KB, Bits: MBit, Cmp: 0},                      // 9
+	{Size: math.MaxInt64, Bits: math.MaxInt64, Cmp: 1},        // 10
+	{Size: math.MinInt64, Bits: math.MinInt64, Cmp: -1},       // 11
+	{Size: math.MaxInt64 / 8, Bits: math.MaxInt64, Cmp: -1},   // 12
+	{Size: math.MaxInt64/8 + 1, Bits: math.MaxInt64, Cmp: 1},  // 13
+	{Size: math.MinInt64 / 8, Bits: math.MinInt64, Cmp: 0},    // 14
+	{Size: math.MinInt64/8 - 1, Bits: math.MinInt64, Cmp: -1}, // 15
+}
+
 func TestSize_Kilobytes(t *testing.T) {
 	for i, test := range sizeConvertTests {
 		if bytes := test.Size.Kilobytes(); bytes != test.KB {
@@ -149,3 +258,943 @@ var sizeConvertTests = []struct {
 		PB:   512.813004996016672,
 	},
 }
+
+func TestSize_Exabytes(t *testing.T) {
+	for i, test := range sizeExabytesTests {
+		if e := test.Size.Exabytes(); e != test.EB {
+			t.Fatalf("Test %d: got %v - want %v", i, e, test.EB)
+		}
+		if e := test.Size.Exbibytes(); e != test.EiB {
+			t.Fatalf("Test %d: got %v - want %v", i, e, test.EiB)
+		}
+	}
+}
+
+var sizeExabytesTests = []struct {
+	Size Size
+	EB   float64
+	EiB  float64
+}{
+	{Size: 0, EB: 0, EiB: 0},                                   // 0
+	{Size: EB, EB: 1, EiB: 0.8673617379884035},                 // 1
+	{Size: EiB, EB: 1.152921504606847, EiB: 1},                 // 2
+	{Size: 1500 * PB, EB: 1.5, EiB: 1.3010426069826053},        // 3
+	{Size: -EiB - 512*PiB, EB: -1.7293822569102705, EiB: -1.5}, // 4
+	{Size: math.MaxInt64, EB: 9.223372036854776, EiB: 8},       // 5
+	{Size: math.MinInt64, EB: -9.223372036854776, EiB: -8},     // 6
+}
+
+func TestSize_Query(t *testing.T) {
+	for i, test := range sizeQueryTests {
+		if q := test.Size.Query(); q != test.Query {
+			t.Fatalf("Test %d: got %s - want %s", i, q, test.Query)
+		}
+	}
+}
+
+var sizeQueryTests = []struct {
+	Size  Size
+	Query string
+}{
+	{Size: 0, Query: "0"},                               // 0
+	{Size: 1*GiB + 512*MiB, Query: "1610612736"},        // 1
+	{Size: -KB, Query: "-1000"},                         // 2
+	{Size: math.MaxInt64, Query: "9223372036854775807"}, // 3
+}
+
+func TestSize_MarshalBinary(t *testing.T) {
+	for i, test := range formatParseSizeTests {
+		data, err := test.MarshalBinary()
+		if err != nil {
+			t.Fatalf("Test %d: failed to marshal size: %v", i, err)
+		}
+		var s Size
+		if err = s.UnmarshalBinary(data); err != nil {
+			t.Fatalf("Test %d: failed to unmarshal size: %v", i, err)
+		}
+		if s != test {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test)
+		}
+	}
+
+	data, _ := (-KiB).MarshalBinary()
+	if want := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfc, 0x00}; !bytes.Equal(data, want) {
+		t.Fatalf("got encoding %x - want %x", data, want)
+	}
+	var s Size
+	if err := s.UnmarshalBinary(data[:7]); err == nil {
+		t.Fatal("Unmarshaling a truncated size should have failed")
+	}
+	if err := s.UnmarshalBinary(append(data, 0)); err == nil {
+		t.Fatal("Unmarshaling an oversized size should have failed")
+	}
+}
+
+func TestSize_Gob(t *testing.T) {
+	type Quota struct {
+		Size      Size
+		BitSize   BitSize
+		Bandwidth Bandwidth
+	}
+	want := Quota{Size: 5 * GiB, BitSize: -3 * Nibble, Bandwidth: 100 * MBitPerSecond}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(want); err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	var got Quota
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if got != want {
+		t.Fatalf("got %+v - want %+v", got, want)
+	}
+}
+
+func TestSize_MarshalText(t *testing.T) {
+	for i, test := range formatParseSizeTests {
+		text, err := test.MarshalText()
+		if err != nil {
+			t.Fatalf("Test %d: failed to marshal size: %v", i, err)
+		}
+		var s Size
+		if err = s.UnmarshalText(text); err != nil {
+			t.Fatalf("Test %d: failed to unmarshal size: %v", i, err)
+		}
+		if s != test {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test)
+		}
+	}
+}
+
+func TestSize_UnmarshalText(t *testing.T) {
+	for i, test := range sizeUnmarshalTextTests {
+		var s Size
+		err := s.UnmarshalText([]byte(test.Text))
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to unmarshal size: %v", i, err)
+		}
+		if s != test.Size {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test.Size)
+		}
+	}
+}
+
+var sizeUnmarshalTextTests = []struct {
+	Text       string
+	Size       Size
+	ShouldFail bool
+}{
+	{Text: "5MiB", Size: 5 * MiB},        // 0
+	{Text: `"5MiB"`, Size: 5 * MiB},      // 1
+	{Text: `"-1.5KB"`, Size: -1500},      // 2
+	{Text: `""`, ShouldFail: true},       // 3
+	{Text: `"`, ShouldFail: true},        // 4
+	{Text: `""5MiB""`, ShouldFail: true}, // 5
+	{Text: `"5MiB`, ShouldFail: true},    // 6
+	{Text: `5MiB"`, ShouldFail: true},    // 7
+	{Text: `'5MiB'`, ShouldFail: true},   // 8
+	{Text: `"5  MiB"`, ShouldFail: true}, // 9
+}
+
+func TestSize_MarshalJSON(t *testing.T) {
+	for i, test := range formatParseSizeTests {
+		data, err := json.Marshal(test)
+		if err != nil {
+			t.Fatalf("Test %d: failed to marshal size: %v", i, err)
+		}
+		if want := `"` + test.String() + `"`; string(data) != want {
+			t.Fatalf("Test %d: got %s - want %s", i, data, want)
+		}
+		var s Size
+		if err = json.Unmarshal(data, &s); err != nil {
+			t.Fatalf("Test %d: failed to unmarshal size: %v", i, err)
+		}
+		if s != test {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test)
+		}
+	}
+}
+
+func TestSize_UnmarshalJSON(t *testing.T) {
+	for i, test := range sizeUnmarshalJSONTests {
+		var s Size
+		err := json.Unmarshal([]byte(test.JSON), &s)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to unmarshal size: %v", i, err)
+		}
+		if s != test.Size {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test.Size)
+		}
+	}
+}
+
+var sizeUnmarshalJSONTests = []struct {
+	JSON       string
+	Size       Size
+	ShouldFail bool
+}{
+	{JSON: `5242880`, Size: 5 * MiB},                   // 0
+	{JSON: `"5MiB"`, Size: 5 * MiB},                    // 1
+	{JSON: `-1500`, Size: -1500},                       // 2
+	{JSON: `"-1.5KB"`, Size: -1500},                    // 3
+	{JSON: `5e6`, Size: 5 * MB},                        // 4
+	{JSON: `1.0`, Size: 1},                             // 5
+	{JSON: `null`, Size: 0},                            // 6
+	{JSON: `9223372036854775807`, Size: math.MaxInt64}, // 7
+	{JSON: `5.5`, ShouldFail: true},                    // 8
+	{JSON: `"5"`, ShouldFail: true},                    // 9
+	{JSON: `""`, ShouldFail: true},                     // 10
+	{JSON: `9223372036854775808`, ShouldFail: true},    // 11
+	{JSON: `1e19`, ShouldFail: true},                   // 12
+	{JSON: `true`, ShouldFail: true},                   // 13
+	{JSON: `"5  MiB"`, ShouldFail: true},               // 14
+}
+
+func TestSize_Value(t *testing.T) {
+	for i, size := range []Size{0, 1, 5 * MiB, -1500, math.MaxInt64, math.MinInt64} {
+		v, err := size.Value()
+		if err != nil {
+			t.Fatalf("Test %d: failed to get value: %v", i, err)
+		}
+		if n, ok := v.(int64); !ok || n != int64(size) {
+			t.Fatalf("Test %d: got %v (%T) - want %d", i, v, v, int64(size))
+		}
+
+		var s Size
+		if err = s.Scan(v); err != nil {
+			t.Fatalf("Test %d: failed to scan value: %v", i, err)
+		}
+		if s != size {
+			t.Fatalf("Test %d: got %d - want %d", i, s, size)
+		}
+	}
+}
+
+func TestSize_Scan(t *testing.T) {
+	for i, test := range sizeScanTests {
+		var s Size
+		err := s.Scan(test.Src)
+		if err == nil && test.ShouldFail {
+			t.Fatalf("Test %d should have failed", i)
+		}
+		if err != nil && !test.ShouldFail {
+			t.Fatalf("Test %d: failed to scan size: %v", i, err)
+		}
+		if s != test.Size {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test.Size)
+		}
+	}
+}
+
+var sizeScanTests = []struct {
+	Src        any
+	Size       Size
+	ShouldFail bool
+}{
+	{Src: int64(5242880), Size: 5 * MiB},    // 0
+	{Src: []byte("5242880"), Size: 5 * MiB}, // 1
+	{Src: "5242880", Size: 5 * MiB},         // 2
+	{Src: "5MiB", Size: 5 * MiB},            // 3
+	{Src: []byte("-1.5KB"), Size: -1500},    // 4
+	{Src: nil, ShouldFail: true},            // 5
+	{Src: 5.5, ShouldFail: true},            // 6
+	{Src: "5.5", ShouldFail: true},          // 7
+	{Src: []byte("five"), ShouldFail: true}, // 8
+	{Src: int(5), ShouldFail: true},         // 9
+}
+
+func TestSize_Format(t *testing.T) {
+	for i, test := range sizeFormatTests {
+		if s := fmt.Sprintf(test.Format, test.Size); s != test.String {
+			t.Fatalf("Test %d: got '%s' - want '%s'", i, s, test.String)
+		}
+	}
+}
+
+var sizeFormatTests = []struct {
+	Format string
+	Size   Size
+	String string
+}{
+	{Format: "%v", Size: 0, String: "0B"},                     // 0
+	{Format: "%s", Size: 1250 * KB, String: "1.25MB"},         // 1
+	{Format: "%d", Size: 1250 * KB, String: "1.25MB"},         // 2
+	{Format: "%b", Size: 1536 * KiB, String: "1.5MiB"},        // 3
+	{Format: "%.2d", Size: 1500 * KiB, String: "1.54MB"},      // 4
+	{Format: "%.1b", Size: 1500 * KiB, String: "1.5MiB"},      // 5
+	{Format: "%.0d", Size: 1500 * KiB, String: "2MB"},         // 6
+	{Format: "%8.1b|", Size: 1500 * KiB, String: "  1.5MiB|"}, // 7
+	{Format: "%-6v|", Size: 512, String: "512B  |"},           // 8
+	{Format: "%+d", Size: MB, String: "+1MB"},                 // 9
+	{Format: "%+d", Size: -MB, String: "-1MB"},                // 10
+	{Format: "% d", Size: MB, String: " 1MB"},                 // 11
+	{Format: "%+6d|", Size: MB, String: "  +1MB|"},            // 12
+	{Format: "%-+6d|", Size: MB, String: "+1MB  |"},           // 13
+	{Format: "%3d", Size: 5 * GB, String: "5GB"},              // 14
+	{Format: "%x", Size: KB, String: "314b42"},                // 15
+	{Format: "%v %v", Size: KB, String: "1KB %!v(MISSING)"},   // 16
+	{Format: "%.3d", Size: math.MaxInt64, String: "9.223EB"},  // 17
+	{Format: "%q", Size: 5 * MB, String: `"5MB"`},             // 18
+	{Format: "%X", Size: KB, String: "314B42"},                // 19
+	{Format: "%-7q|", Size: 5 * MB, String: `"5MB"  |`},       // 20
+	{Format: "% x", Size: KB, String: "31 4b 42"},             // 21
+	{Format: "%.2q", Size: 5 * MB, String: `"5M"`},            // 22
+}
+
+func TestSize_Delta(t *testing.T) {
+	for i, test := range sizeDeltaTests {
+		delta, grew := test.Size.Delta(test.From)
+		if delta != test.Delta {
+			t.Fatalf("Test %d: got delta %d - want %d", i, delta, test.Delta)
+		}
+		if grew != test.Grew {
+			t.Fatalf("Test %d: got grew %v - want %v", i, grew, test.Grew)
+		}
+	}
+}
+
+var sizeDeltaTests = []struct {
+	Size, From Size
+	Delta      Size
+	Grew       bool
+}{
+	{Size: 0, From: 0, Delta: 0, Grew: false},                                     // 0
+	{Size: 3 * MB, From: MB, Delta: 2 * MB, Grew: true},                           // 1
+	{Size: MB, From: 3 * MB, Delta: 2 * MB, Grew: false},                          // 2
+	{Size: -MB, From: MB, Delta: 2 * MB, Grew: false},                             // 3
+	{Size: math.MaxInt64, From: math.MinInt64, Delta: math.MaxInt64, Grew: true},  // 4
+	{Size: math.MinInt64, From: math.MaxInt64, Delta: math.MaxInt64, Grew: false}, // 5
+}
+
+func TestSize_Kilobits(t *testing.T) {
+	for i, test := range sizeConvertBitsTests {
+		if bits := test.Size.Kilobits(); bits != test.KBit {
+			t.Fatalf("Test %d: got %f - want %f", i, bits, test.KBit)
+		}
+	}
+}
+
+func TestSize_Megabits(t *testing.T) {
+	for i, test := range sizeConvertBitsTests {
+		if bits := test.Size.Megabits(); bits != test.MBit {
+			t.Fatalf("Test %d: got %f - want %f", i, bits, test.MBit)
+		}
+	}
+}
+
+func TestSize_Gigabits(t *testing.T) {
+	for i, test := range sizeConvertBitsTests {
+		if bits := test.Size.Gigabits(); bits != test.GBit {
+			t.Fatalf("Test %d: got %f - want %f", i, bits, test.GBit)
+		}
+	}
+}
+
+func TestSize_Terabits(t *testing.T) {
+	for i, test := range sizeConvertBitsTests {
+		if bits := test.Size.Terabits(); bits != test.TBit {
+			t.Fatalf("Test %d: got %f - want %f", i, bits, test.TBit)
+		}
+	}
+}
+
+var sizeConvertBitsTests = []struct {
+	Size Size
+	KBit float64
+	MBit float64
+	GBit float64
+	TBit float64
+}{
+	{ // 0
+		Size: Byte,
+		KBit: 0.008,
+		MBit: 0.000008,
+		GBit: 0.000000008,
+		TBit: 0.000000000008,
+	},
+	{ // 1
+		Size: 125 * Byte,
+		KBit: 1,
+		MBit: 0.001,
+		GBit: 0.000001,
+		TBit: 0.000000001,
+	},
+	{ // 2
+		Size: 12*MB + 500*KB,
+		KBit: 100000,
+		MBit: 100,
+		GBit: 0.1,
+		TBit: 0.0001,
+	},
+	{ // 3
+		Size: 125 * GB,
+		KBit: 1000000000,
+		MBit: 1000000,
+		GBit: 1000,
+		TBit: 1,
+	},
+	{ // 4
+		Size: math.MaxInt64,
+		KBit: 73786976294838206.456,
+		MBit: 73786976294838.206456,
+		GBit: 73786976294.838206456,
+		TBit: 73786976.294838206456,
+	},
+}
+
+func TestSize_Log2(t *testing.T) {
+	for i, test := range sizeLog2Tests {
+		if log := test.Size.Log2(); log != test.Log2 {
+			t.Fatalf("Test %d: got %d - want %d", i, log, test.Log2)
+		}
+		if bucket := test.Size.Bucket(); bucket != test.Bucket {
+			t.Fatalf("Test %d: got bucket %d - want %d", i, bucket, test.Bucket)
+		}
+	}
+}
+
+var sizeLog2Tests = []struct {
+	Size   Size
+	Log2   int
+	Bucket Size
+}{
+	{Size: math.MinInt64, Log2: -1, Bucket: 0},             // 0
+	{Size: -1, Log2: -1, Bucket: 0},                        // 1
+	{Size: 0, Log2: -1, Bucket: 0},                         // 2
+	{Size: 1, Log2: 0, Bucket: 1},                          // 3
+	{Size: 2, Log2: 1, Bucket: 2},                          // 4
+	{Size: 3, Log2: 1, Bucket: 4},                          // 5
+	{Size: KiB, Log2: 10, Bucket: KiB},                     // 6
+	{Size: KiB + 512, Log2: 10, Bucket: 2 * KiB},           // 7
+	{Size: MB, Log2: 19, Bucket: MiB},                      // 8
+	{Size: 1 << 62, Log2: 62, Bucket: 1 << 62},             // 9
+	{Size: 1<<62 + 1, Log2: 62, Bucket: math.MaxInt64},     // 10
+	{Size: math.MaxInt64, Log2: 62, Bucket: math.MaxInt64}, // 11
+}
+
+func TestSize_Saved(t *testing.T) {
+	for i, test := range sizeSavedTests {
+		if saved := test.Original.Saved(test.Stored); saved != test.Saved {
+			t.Fatalf("Test %d: got %d - want %d", i, saved, test.Saved)
+		}
+		if e := test.Original.Efficiency(test.Stored); e != test.Efficiency {
+			t.Fatalf("Test %d: got efficiency %f - want %f", i, e, test.Efficiency)
+		}
+	}
+}
+
+var sizeSavedTests = []struct {
+	Original, Stored Size
+	Saved            Size
+	Efficiency       float64
+}{
+	{Original: 0, Stored: 0, Saved: 0, Efficiency: 0},                                             // 0
+	{Original: 0, Stored: MB, Saved: -MB, Efficiency: 0},                                          // 1
+	{Original: 4 * MB, Stored: MB, Saved: 3 * MB, Efficiency: 0.25},                               // 2
+	{Original: MB, Stored: 2 * MB, Saved: -MB, Efficiency: 2},                                     // 3
+	{Original: GB, Stored: GB, Saved: 0, Efficiency: 1},                                           // 4
+	{Original: math.MaxInt64, Stored: -1, Saved: math.MaxInt64, Efficiency: -1.0 / math.MaxInt64}, // 5
+	{Original: math.MinInt64, Stored: 1, Saved: math.MinInt64, Efficiency: -1.0 / (1 << 63)},      // 6
+}
+
+func TestSize_Times(t *testing.T) {
+	for i, test := range sizeTimesTests {
+		if p := test.Size.Times(test.N); p != test.Product {
+			t.Fatalf("Test %d: got %d - want %d", i, p, test.Product)
+		}
+	}
+}
+
+func TestSize_Mul(t *testing.T) {
+	for i, test := range sizeTimesTests {
+		p, overflow := test.Size.Mul(test.N)
+		if p != test.Product {
+			t.Fatalf("Test %d: got %d - want %d", i, p, test.Product)
+		}
+		if overflow != test.Overflow {
+			t.Fatalf("Test %d: got overflow %v - want %v", i, overflow, test.Overflow)
+		}
+	}
+}
+
+func TestSize_AddSub(t *testing.T) {
+	for i, test := range sizeAddSubTests {
+		sum, overflow := test.S.Add(test.T)
+		if sum != test.Sum || overflow != test.SumOverflow {
+			t.Fatalf("Test %d: got sum (%d, %v) - want (%d, %v)", i, sum, overflow, test.Sum, test.SumOverflow)
+		}
+		diff, overflow := test.S.Sub(test.T)
+		if diff != test.Diff || overflow != test.DiffOverflow {
+			t.Fatalf("Test %d: got diff (%d, %v) - want (%d, %v)", i, diff, overflow, test.Diff, test.DiffOverflow)
+		}
+	}
+}
+
+var sizeAddSubTests = []struct {
+	S, T         Size
+	Sum          Size
+	SumOverflow  bool
+	Diff         Size
+	DiffOverflow bool
+}{
+	{S: 0, T: 0, Sum: 0, Diff: 0},                                                             // 0
+	{S: MB, T: KB, Sum: MB + KB, Diff: MB - KB},                                               // 1
+	{S: -MB, T: KB, Sum: -MB + KB, Diff: -MB - KB},                                            // 2
+	{S: math.MaxInt64, T: 0, Sum: math.MaxInt64, Diff: math.MaxInt64},                         // 3
+	{S: math.MaxInt64, T: 1, Sum: math.MaxInt64, SumOverflow: true, Diff: math.MaxInt64 - 1},  // 4
+	{S: math.MinInt64, T: 1, Sum: math.MinInt64 + 1, Diff: math.MinInt64, DiffOverflow: true}, // 5
+	{S: math.MinInt64, T: -1, Sum: math.MinInt64, SumOverflow: true, Diff: math.MinInt64 + 1}, // 6
+	{S: 0, T: math.MinInt64, Sum: math.MinInt64, Diff: math.MaxInt64, DiffOverflow: true},     // 7
+	{S: 5 * EB, T: 5 * EB, Sum: math.MaxInt64, SumOverflow: true, Diff: 0},                    // 8
+	{S: -5 * EB, T: 5 * EB, Sum: 0, Diff: math.MinInt64, DiffOverflow: true},                  // 9
+	{S: math.MaxInt64 - KB, T: KB, Sum: math.MaxInt64, Diff: math.MaxInt64 - 2*KB},            // 10
+}
+
+var sizeTimesTests = []struct {
+	Size     Size
+	N        int64
+	Product  Size
+	Overflow bool
+}{
+	{Size: 0, N: 1000, Product: 0},                                                  // 0
+	{Size: KB, N: 0, Product: 0},                                                    // 1
+	{Size: 512, N: 2, Product: KiB},                                                 // 2
+	{Size: MB, N: -3, Product: -3 * MB},                                             // 3
+	{Size: 4 * KiB, N: 1 << 40, Product: 4 * PiB},                                   // 4
+	{Size: 4096 * PiB, N: 2, Product: math.MaxInt64, Overflow: true},                // 5
+	{Size: -4096 * PiB, N: 3, Product: math.MinInt64, Overflow: true},               // 6
+	{Size: 4096 * PiB, N: -3, Product: math.MinInt64, Overflow: true},               // 7
+	{Size: math.MaxInt64, N: math.MaxInt64, Product: math.MaxInt64, Overflow: true}, // 8
+	{Size: math.MinInt64, N: math.MaxInt64, Product: math.MinInt64, Overflow: true}, // 9
+	{Size: math.MinInt64, N: -1, Product: math.MaxInt64, Overflow: true},            // 10
+	{Size: math.MinInt64, N: 1, Product: math.MinInt64},                             // 11
+	{Size: math.MaxInt64, N: 1, Product: math.MaxInt64},                             // 12
+	{Size: -math.MaxInt64, N: -1, Product: math.MaxInt64},                           // 13
+}
+
+func TestSize_MinMax(t *testing.T) {
+	for i, test := range sizeMinMaxTests {
+		if min := test.A.Min(test.B); min != test.Min {
+			t.Fatalf("Test %d: got min %d - want %d", i, min, test.Min)
+		}
+		if min := test.B.Min(test.A); min != test.Min {
+			t.Fatalf("Test %d: got min %d - want %d", i, min, test.Min)
+		}
+		if max := test.A.Max(test.B); max != test.Max {
+			t.Fatalf("Test %d: got max %d - want %d", i, max, test.Max)
+		}
+		if max := test.B.Max(test.A); max != test.Max {
+			t.Fatalf("Test %d: got max %d - want %d", i, max, test.Max)
+		}
+		if min := Min(test.A, test.B); min != test.Min {
+			t.Fatalf("Test %d: got min %d - want %d", i, min, test.Min)
+		}
+		if max := Max(test.A, test.B); max != test.Max {
+			t.Fatalf("Test %d: got max %d - want %d", i, max, test.Max)
+		}
+	}
+}
+
+func TestSize_Compare(t *testing.T) {
+	for i, test := range sizeCompareTests {
+		if c := test.A.Compare(test.B); c != test.Cmp {
+			t.Fatalf("Test %d: got %d - want %d", i, c, test.Cmp)
+		}
+		if c := test.B.Compare(test.A); c != -test.Cmp {
+			t.Fatalf("Test %d: got %d - want %d", i, c, -test.Cmp)
+		}
+	}
+}
+
+func TestSize_Sign(t *testing.T) {
+	for i, test := range signTests {
+		s := Size(test.Value)
+		if sign := s.Sign(); sign != test.Sign {
+			t.Fatalf("Test %d: got sign %d - want %d", i, sign, test.Sign)
+		}
+		if z := s.IsZero(); z != (test.Sign == 0) {
+			t.Fatalf("Test %d: got IsZero %v - want %v", i, z, test.Sign == 0)
+		}
+		if n := s.IsNegative(); n != (test.Sign < 0) {
+			t.Fatalf("Test %d: got IsNegative %v - want %v", i, n, test.Sign < 0)
+		}
+	}
+}
+
+var signTests = []struct {
+	Value int64
+	Sign  int
+}{
+	{Value: 0, Sign: 0},              // 0
+	{Value: 1, Sign: 1},              // 1
+	{Value: -1, Sign: -1},            // 2
+	{Value: math.MaxInt64, Sign: 1},  // 3
+	{Value: math.MinInt64, Sign: -1}, // 4
+}
+
+var sizeCompareTests = []struct {
+	A, B Size
+	Cmp  int
+}{
+	{A: 0, B: 0, Cmp: 0},                          // 0
+	{A: KB, B: MB, Cmp: -1},                       // 1
+	{A: KiB, B: KB, Cmp: 1},                       // 2
+	{A: -KB, B: KB, Cmp: -1},                      // 3
+	{A: math.MinInt64, B: math.MaxInt64, Cmp: -1}, // 4
+	{A: math.MaxInt64, B: -1, Cmp: 1},             // 5
+	{A: math.MinInt64, B: math.MinInt64, Cmp: 0},  // 6
+}
+
+func TestSize_Round(t *testing.T) {
+	for i, test := range sizeRoundTests {
+		if r := test.Size.Round(test.M); r != test.Round {
+			t.Fatalf("Test %d: got %d - want %d", i, r, test.Round)
+		}
+	}
+}
+
+var sizeRoundTests = []struct {
+	Size  Size
+	M     Size
+	Round Size
+}{
+	{Size: 0, M: KB, Round: 0},                                // 0
+	{Size: 1001, M: KB, Round: KB},                            // 1
+	{Size: 1500, M: KB, Round: 2 * KB},                        // 2
+	{Size: -1001, M: KB, Round: -KB},                          // 3
+	{Size: -1499, M: KB, Round: -KB},                          // 4
+	{Size: -1500, M: KB, Round: -2 * KB},                      // 5
+	{Size: -999, M: KB, Round: -KB},                           // 6
+	{Size: -1001, M: 0, Round: -1001},                         // 7
+	{Size: math.MinInt64, M: KB, Round: math.MinInt64},        // 8
+	{Size: math.MinInt64 + 1, M: 3, Round: math.MinInt64 + 2}, // 9
+}
+
+func TestSize_Clamp(t *testing.T) {
+	for i, test := range sizeClampTests {
+		if c := test.Size.Clamp(test.Min, test.Max); c != test.Clamp {
+			t.Fatalf("Test %d: got %d - want %d", i, c, test.Clamp)
+		}
+	}
+}
+
+var sizeClampTests = []struct {
+	Size, Min, Max Size
+	Clamp          Size
+}{
+	{Size: 0, Min: 0, Max: 0, Clamp: 0},                                     // 0
+	{Size: 512, Min: KB, Max: 16 * MB, Clamp: KB},                           // 1
+	{Size: GB, Min: KB, Max: 16 * MB, Clamp: 16 * MB},                       // 2
+	{Size: MB, Min: KB, Max: 16 * MB, Clamp: MB},                            // 3
+	{Size: KB, Min: KB, Max: KB, Clamp: KB},                                 // 4
+	{Size: MB, Min: 16 * MB, Max: KB, Clamp: MB},                            // 5
+	{Size: -MB, Min: -KB, Max: KB, Clamp: -KB},                              // 6
+	{Size: math.MinInt64, Min: math.MinInt64, Max: 0, Clamp: math.MinInt64}, // 7
+	{Size: math.MaxInt64, Min: 0, Max: EB, Clamp: EB},                       // 8
+}
+
+var sizeMinMaxTests = []struct {
+	A, B     Size
+	Min, Max Size
+}{
+	{A: 0, B: 0, Min: 0, Max: 0},                                                         // 0
+	{A: KB, B: MB, Min: KB, Max: MB},                                                     // 1
+	{A: -KB, B: KB, Min: -KB, Max: KB},                                                   // 2
+	{A: math.MinInt64, B: math.MaxInt64, Min: math.MinInt64, Max: math.MaxInt64},         // 3
+	{A: math.MaxInt64, B: math.MaxInt64 - 1, Min: math.MaxInt64 - 1, Max: math.MaxInt64}, // 4
+	{A: math.MinInt64, B: math.MinInt64 + 1, Min: math.MinInt64, Max: math.MinInt64 + 1}, // 5
+}
+
+func TestSize_IsAligned(t *testing.T) {
+	for i, test := range sizeIsAlignedTests {
+		if aligned := test.Size.IsAligned(test.Boundary); aligned != test.Aligned {
+			t.Fatalf("Test %d: got %v - want %v", i, aligned, test.Aligned)
+		}
+	}
+}
+
+var sizeIsAlignedTests = []struct {
+	Size     Size
+	Boundary Size
+	Aligned  bool
+}{
+	{Size: 0, Boundary: 4 * KiB, Aligned: true},                   // 0
+	{Size: 8 * KiB, Boundary: 4 * KiB, Aligned: true},             // 1
+	{Size: 8*KiB + 1, Boundary: 4 * KiB, Aligned: false},          // 2
+	{Size: -4 * KiB, Boundary: 4 * KiB, Aligned: true},            // 3
+	{Size: 512, Boundary: 4 * KiB, Aligned: false},                // 4
+	{Size: 4 * KiB, Boundary: 0, Aligned: false},                  // 5
+	{Size: 4 * KiB, Boundary: -512, Aligned: false},               // 6
+	{Size: math.MinInt64, Boundary: 4 * KiB, Aligned: true},       // 7
+	{Size: math.MaxInt64, Boundary: 4 * KiB, Aligned: false},      // 8
+	{Size: math.MaxInt64, Boundary: math.MaxInt64, Aligned: true}, // 9
+}
+
+func TestSize_ChunkCount(t *testing.T) {
+	for i, test := range sizeChunkCountTests {
+		if n := test.Size.ChunkCount(test.Chunk); n != test.N {
+			t.Fatalf("Test %d: got %d - want %d", i, n, test.N)
+		}
+	}
+}
+
+var sizeChunkCountTests = []struct {
+	Size  Size
+	Chunk Size
+	N     int
+}{
+	{Size: 0, Chunk: MB, N: 0},                        // 0
+	{Size: 10 * MB, Chunk: 4 * MB, N: 3},              // 1
+	{Size: 8 * MB, Chunk: 4 * MB, N: 2},               // 2
+	{Size: 1, Chunk: 4 * MB, N: 1},                    // 3
+	{Size: 10 * MB, Chunk: 0, N: 0},                   // 4
+	{Size: 10 * MB, Chunk: -MB, N: 0},                 // 5
+	{Size: -10 * MB, Chunk: MB, N: 0},                 // 6
+	{Size: math.MaxInt64, Chunk: math.MaxInt64, N: 1}, // 7
+	{Size: math.MaxInt64, Chunk: 2, N: 1 << 62},       // 8
+	{Size: math.MaxInt64, Chunk: 1 << 62, N: 2},       // 9
+}
+
+func TestSize_SignificantFigures(t *testing.T) {
+	for i, test := range sizeSignificantFiguresTests {
+		if s := test.Size.SignificantFigures(test.N); s != test.Result {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test.Result)
+		}
+	}
+}
+
+var sizeSignificantFiguresTests = []struct {
+	Size   Size
+	N      int
+	Result Size
+}{
+	{Size: 0, N: 3, Result: 0},                               // 0
+	{Size: 1234567890, N: 3, Result: 1230000000},             // 1
+	{Size: 1234567890, N: 0, Result: 1234567890},             // 2
+	{Size: 1234567890, N: -1, Result: 1234567890},            // 3
+	{Size: 1234567890, N: 1, Result: GB},                     // 4
+	{Size: 1234567890, N: 10, Result: 1234567890},            // 5
+	{Size: 1234567890, N: 20, Result: 1234567890},            // 6
+	{Size: -1234567890, N: 2, Result: -1200000000},           // 7
+	{Size: 1250, N: 2, Result: 1300},                         // 8
+	{Size: -1250, N: 2, Result: -1300},                       // 9
+	{Size: 9999, N: 2, Result: 10 * KB},                      // 10
+	{Size: 42, N: 3, Result: 42},                             // 11
+	{Size: 5, N: 1, Result: 5},                               // 12
+	{Size: math.MaxInt64, N: 1, Result: 9 * 1e18},            // 13
+	{Size: math.MaxInt64, N: 3, Result: 9220000000000000000}, // 14
+	{Size: math.MinInt64, N: 1, Result: -9 * 1e18},           // 15
+	{Size: math.MinInt64, N: 19, Result: math.MinInt64},      // 16
+}
+
+func TestSize_NearestUnit(t *testing.T) {
+	for i, test := range sizeNearestUnitTests {
+		unit, label := test.Size.NearestUnit()
+		if unit != test.Unit || label != test.Label {
+			t.Fatalf("Test %d: got (%d, %s) - want (%d, %s)", i, unit, label, test.Unit, test.Label)
+		}
+		if s := FormatSize(test.Size, 'D', -1); !strings.HasSuffix(s, label) {
+			t.Fatalf("Test %d: '%s' is not formatted with unit %s", i, s, label)
+		}
+	}
+}
+
+var sizeNearestUnitTests = []struct {
+	Size  Size
+	Unit  Size
+	Label string
+}{
+	{Size: 0, Unit: Byte, Label: "B"},            // 0
+	{Size: 999, Unit: Byte, Label: "B"},          // 1
+	{Size: KB, Unit: KB, Label: "KB"},            // 2
+	{Size: -KiB, Unit: KB, Label: "KB"},          // 3
+	{Size: 999 * MB, Unit: MB, Label: "MB"},      // 4
+	{Size: 1200 * MB, Unit: GB, Label: "GB"},     // 5
+	{Size: TiB, Unit: TB, Label: "TB"},           // 6
+	{Size: math.MaxInt64, Unit: EB, Label: "EB"}, // 7
+	{Size: math.MinInt64, Unit: EB, Label: "EB"}, // 8
+	{Size: 999 * PB, Unit: PB, Label: "PB"},      // 9
+}
+
+func TestSize_AsBinary(t *testing.T) {
+	for i, test := range sizeAsBinaryTests {
+		if b := test.Decimal.AsBinary(); b != test.Binary {
+			t.Fatalf("Test %d: got %d - want %d", i, b, test.Binary)
+		}
+	}
+}
+
+var sizeAsBinaryTests = []struct {
+	Decimal, Binary Size
+}{
+	{Decimal: 0, Binary: 0},                         // 0
+	{Decimal: 999, Binary: 999},                     // 1
+	{Decimal: KB, Binary: KiB},                      // 2
+	{Decimal: GB, Binary: GiB},                      // 3
+	{Decimal: -GB, Binary: -GiB},                    // 4
+	{Decimal: 1500 * MB, Binary: 1*GiB + 512*MiB},   // 5
+	{Decimal: 1*KB + 1, Binary: 1025},               // 6
+	{Decimal: 8 * PB, Binary: 8 * PiB},              // 7
+	{Decimal: math.MaxInt64, Binary: math.MaxInt64}, // 8
+	{Decimal: math.MinInt64, Binary: math.MinInt64}, // 9
+}
+
+func TestSize_AsDecimal(t *testing.T) {
+	for i, test := range sizeAsDecimalTests {
+		if d := test.Binary.AsDecimal(); d != test.Decimal {
+			t.Fatalf("Test %d: got %d - want %d", i, d, test.Decimal)
+		}
+	}
+}
+
+var sizeAsDecimalTests = []struct {
+	Binary, Decimal Size
+}{
+	{Binary: 0, Decimal: 0},                               // 0
+	{Binary: 1023, Decimal: 1023},                         // 1
+	{Binary: KiB, Decimal: KB},                            // 2
+	{Binary: GiB, Decimal: GB},                            // 3
+	{Binary: -GiB, Decimal: -GB},                          // 4
+	{Binary: 1*GiB + 512*MiB, Decimal: 1500 * MB},         // 5
+	{Binary: 1025, Decimal: 1001},                         // 6
+	{Binary: math.MaxInt64, Decimal: 7999999999999999999}, // 7
+	{Binary: math.MinInt64, Decimal: -8 * EB},             // 8
+}
+
+func TestCoalesce(t *testing.T) {
+	for i, test := range coalesceTests {
+		if s := Coalesce(test.Sizes...); s != test.Size {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test.Size)
+		}
+	}
+}
+
+var coalesceTests = []struct {
+	Sizes []Size
+	Size  Size
+}{
+	{Sizes: nil, Size: 0},                  // 0
+	{Sizes: []Size{0, 0}, Size: 0},         // 1
+	{Sizes: []Size{KB}, Size: KB},          // 2
+	{Sizes: []Size{0, MB, KB}, Size: MB},   // 3
+	{Sizes: []Size{0, -KB, KB}, Size: -KB}, // 4
+	{Sizes: []Size{GB, 0, KB}, Size: GB},   // 5
+}
+
+func TestGeometricMeanSize(t *testing.T) {
+	for i, test := range geometricMeanSizeTests {
+		if s := GeometricMeanSize(test.Sizes...); s != test.Mean {
+			t.Fatalf("Test %d: got %d - want %d", i, s, test.Mean)
+		}
+	}
+}
+
+var geometricMeanSizeTests = []struct {
+	Sizes []Size
+	Mean  Size
+}{
+	{Sizes: nil, Mean: 0},                                     // 0
+	{Sizes: []Size{KB}, Mean: KB},                             // 1
+	{Sizes: []Size{KB, MB}, Mean: 31623},                      // 2
+	{Sizes: []Size{2, 8}, Mean: 4},                            // 3
+	{Sizes: []Size{KB, MB, GB}, Mean: MB},                     // 4
+	{Sizes: []Size{KB, 0, GB}, Mean: 0},                       // 5
+	{Sizes: []Size{KB, -MB}, Mean: 0},                         // 6
+	{Sizes: []Size{PB, PB}, Mean: PB},                         // 7
+	{Sizes: []Size{4 * KiB, 4 * KiB, 4 * KiB}, Mean: 4 * KiB}, // 8
+	{Sizes: []Size{PB, PB}, Mean: PB},                         // 9
+}
+
+func TestFreeSpace(t *testing.T) {
+	for i, test := range freeSpaceTests {
+		if free := FreeSpace(test.Capacity, test.Used); free != test.Free {
+			t.Fatalf("Test %d: got %d - want %d", i, free, test.Free)
+		}
+	}
+}
+
+func TestUsedPercent(t *testing.T) {
+	for i, test := range freeSpaceTests {
+		if p := UsedPercent(test.Capacity, test.Used); p != test.Percent {
+			t.Fatalf("Test %d: got %f - want %f", i, p, test.Percent)
+		}
+	}
+}
+
+var freeSpaceTests = []struct {
+	Capacity, Used Size
+	Free           Size
+	Percent        float64
+}{
+	{Capacity: 0, Used: 0, Free: 0, Percent: 0},                                     // 0
+	{Capacity: 100 * GB, Used: 25 * GB, Free: 75 * GB, Percent: 25},                 // 1
+	{Capacity: 100 * GB, Used: 100 * GB, Free: 0, Percent: 100},                     // 2
+	{Capacity: 100 * GB, Used: 120 * GB, Free: 0, Percent: 100},                     // 3
+	{Capacity: 100 * GB, Used: -1 * GB, Free: 101 * GB, Percent: 0},                 // 4
+	{Capacity: -1 * GB, Used: 0, Free: 0, Percent: 0},                               // 5
+	{Capacity: math.MaxInt64, Used: math.MinInt64, Free: math.MaxInt64, Percent: 0}, // 6
+	{Capacity: math.MinInt64, Used: math.MaxInt64, Free: 0, Percent: 0},             // 7
+	{Capacity: math.MaxInt64, Used: math.MaxInt64, Free: 0, Percent: 100},           // 8
+}
+
+func TestOSPageSize(t *testing.T) {
+	size := OSPageSize()
+	if size <= 0 {
+		t.Fatalf("Invalid page size: %d", size)
+	}
+	if size.Bucket() != size {
+		t.Fatalf("Page size %d is not a power of two", size)
+	}
+}
+
+func TestSize_Ratio(t *testing.T) {
+	for i, test := range sizeRatioTests {
+		if r := test.A.Ratio(test.B); r != test.Ratio {
+			t.Fatalf("Test %d: got %v - want %v", i, r, test.Ratio)
+		}
+	}
+}
+
+var sizeRatioTests = []struct {
+	A, B  Size
+	Ratio float64
+}{
+	{A: 0, B: 0, Ratio: 0},                         // 0
+	{A: GB, B: 0, Ratio: 0},                        // 1
+	{A: 0, B: GB, Ratio: 0},                        // 2
+	{A: 3200 * MB, B: GB, Ratio: 3.2},              // 3
+	{A: GB, B: 4 * GB, Ratio: 0.25},                // 4
+	{A: -GB, B: 2 * GB, Ratio: -0.5},               // 5
+	{A: math.MinInt64, B: math.MinInt64, Ratio: 1}, // 6
+}
+
+func TestSize_Words(t *testing.T) {
+	for i, test := range sizeWordsTests {
+		if w := test.Size.Words(); w != test.Words {
+			t.Fatalf("Test %d: got '%s' - want '%s'", i, w, test.Words)
+		}
+	}
+}
+
+var sizeWordsTests = []struct {
+	Size  Size
+	Words string
+}{
+	{Size: 0, Words: "0 bytes"},                    // 0
+	{Size: 1, Words: "1 byte"},                     // 1
+	{Size: 2, Words: "2 bytes"},                    // 2
+	{Size: KB, Words: "1 kilobyte"},                // 3
+	{Size: -1500, Words: "-1 kilobyte, 500 bytes"}, // 4
+	{Size: PB + 234*TB + 56*GB, Words: "1 petabyte, 234 terabytes, 56 gigabytes"},                                                    // 5
+	{Size: 2*MB + 1, Words: "2 megabytes, 1 byte"},                                                                                   // 6
+	{Size: KiB, Words: "1 kilobyte, 24 bytes"},                                                                                       // 7
+	{Size: math.MaxInt64, Words: "9 exabytes, 223 petabytes, 372 terabytes, 36 gigabytes, 854 megabytes, 775 kilobytes, 807 bytes"},  // 8
+	{Size: math.MinInt64, Words: "-9 exabytes, 223 petabytes, 372 terabytes, 36 gigabytes, 854 megabytes, 775 kilobytes, 808 bytes"}, // 9
+}